@@ -0,0 +1,111 @@
+package config
+
+import (
+	"flag"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"termidoro/notify"
+)
+
+// alertChannels are the notifiers an alert can be sent through.
+var alertChannels = []string{"desktop", "sound", "bell", "terminal"}
+
+// defaultAlertVia includes the terminal, which stands in for the desktop
+// over SSH and is otherwise off.
+var defaultAlertVia = []string{"desktop", "sound", "terminal"}
+
+// Alerts configures the alerts raised while a session runs.
+type Alerts struct {
+	// BeforeEnd lists how long before the end of a session to warn.
+	BeforeEnd []time.Duration
+	// Milestones lists the percentages of a session to announce, e.g. 50
+	// for halfway.
+	Milestones []int
+	// Via names the notifiers the alerts are sent through.
+	Via []string
+	// Breaks raises the alerts during breaks as well as work sessions.
+	Breaks bool
+}
+
+var (
+	warnFlag       string
+	milestonesFlag string
+	alertViaFlag   string
+)
+
+func registerAlertFlags() {
+	flag.StringVar(&warnFlag, "warn", "", "Warn this long before a session ends, e.g. 5m,2m")
+	flag.StringVar(&milestonesFlag, "milestones", "", "Announce these percentages of a session, e.g. 50 for halfway")
+	flag.StringVar(&alertViaFlag, "alert-via", "", "Send alerts through desktop, sound, bell and/or terminal (default desktop,sound,terminal)")
+}
+
+// resolveAlerts combines the alert flags with the [alerts] table. Without
+// any alerts, a configured warning sound plays one minute before the end.
+func resolveAlerts(fa fileAlerts, sounds map[string]notify.SoundSetting) (Alerts, error) {
+	alerts := Alerts{Breaks: fa.Breaks}
+
+	warnings := fa.BeforeEnd
+	if warnFlag != "" {
+		warnings = splitList(warnFlag)
+	}
+	for _, w := range warnings {
+		d, err := parseDuration(w)
+		if err != nil || d <= 0 {
+			return alerts, fmt.Errorf("invalid warning %q: expected a duration such as 2m", w)
+		}
+		alerts.BeforeEnd = append(alerts.BeforeEnd, d)
+	}
+
+	alerts.Milestones = fa.Milestones
+	if milestonesFlag != "" {
+		alerts.Milestones = nil
+		for _, m := range splitList(milestonesFlag) {
+			percent, err := strconv.Atoi(strings.TrimSuffix(m, "%"))
+			if err != nil {
+				return alerts, fmt.Errorf("invalid milestone %q: expected a percentage such as 50", m)
+			}
+			alerts.Milestones = append(alerts.Milestones, percent)
+		}
+	}
+	for _, m := range alerts.Milestones {
+		if m <= 0 || m >= 100 {
+			return alerts, fmt.Errorf("milestone %d%% must be between 1 and 99", m)
+		}
+	}
+
+	alerts.Via = fa.Via
+	if alertViaFlag != "" {
+		alerts.Via = splitList(alertViaFlag)
+	}
+	for _, via := range alerts.Via {
+		if !slices.Contains(alertChannels, via) {
+			return alerts, fmt.Errorf("unknown alert channel %q (expected one of %s)", via, strings.Join(alertChannels, ", "))
+		}
+	}
+
+	if len(alerts.BeforeEnd) == 0 && len(alerts.Milestones) == 0 && sounds[notify.SoundWarning].File != "" {
+		alerts.BeforeEnd = []time.Duration{time.Minute}
+		if len(alerts.Via) == 0 {
+			alerts.Via = []string{"sound"}
+		}
+	}
+	if len(alerts.Via) == 0 {
+		alerts.Via = defaultAlertVia
+	}
+	return alerts, nil
+}
+
+// splitList splits a comma-separated flag value.
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
@@ -4,10 +4,14 @@ import (
 	"flag"
 	"fmt"
 	"os"
+	"slices"
+	"sort"
 	"strings"
 	"time"
 
 	"github.com/sahilm/fuzzy"
+
+	"termidoro/notify"
 )
 
 var (
@@ -16,30 +20,149 @@ var (
 	noSoundFlag       bool
 	workFlag          string
 	breakFlag         string
+	longBreakFlag     string
+	longBreakEvery    int
 	nameFlag          string
 	templateFlag      string
 	listTemplatesFlag bool
+	configFlag        string
+	outputFlag        string
+	jsonIntervalFlag  string
+	noHooksFlag       bool
+	noDesktopFlag     bool
+	bellFlag          bool
+	terminalFlag      bool
+	taskFlag          string
+	actionsFlag       bool
+	notifyModeFlag    string
+	voidAfterFlag     int
+	journalFlag       bool
+	intentionsFlag    bool
+)
+
+const (
+	defaultLongBreakDuration = 15 * time.Minute
+	defaultLongBreakEvery    = 4
 )
 
 type Template struct {
-	WorkDuration  time.Duration
-	BreakDuration time.Duration
-	Name          string
+	WorkDuration      time.Duration
+	BreakDuration     time.Duration
+	Name              string
+	LongBreakDuration time.Duration
+	LongBreakEvery    int
 }
 
 var templates = map[string]Template{
-	"deep-work": {50 * time.Minute, 10 * time.Minute, "Deep Work"},
-	"sprint":    {15 * time.Minute, 3 * time.Minute, "Sprint"},
-	"focus":     {25 * time.Minute, 5 * time.Minute, "Focus"},
-	"study":     {45 * time.Minute, 15 * time.Minute, "Study"},
+	"deep-work": {50 * time.Minute, 10 * time.Minute, "Deep Work", 30 * time.Minute, 3},
+	"sprint":    {15 * time.Minute, 3 * time.Minute, "Sprint", 10 * time.Minute, 4},
+	"focus":     {25 * time.Minute, 5 * time.Minute, "Focus", 15 * time.Minute, 4},
+	"study":     {45 * time.Minute, 15 * time.Minute, "Study", 30 * time.Minute, 4},
+}
+
+// Output modes for --output.
+const (
+	OutputText = "text"
+	OutputJSON = "json"
+)
+
+// Subcommands recognised as the first argument.
+const (
+	CommandStats  = "stats"
+	CommandStatus = "status"
+	CommandPause  = "pause"
+	CommandResume = "resume"
+	CommandToggle = "toggle"
+	CommandSkip   = "skip"
+	CommandExtend = "extend"
+	CommandStop   = "stop"
+	CommandSnooze = "snooze"
+	CommandTask   = "task"
+	CommandLog    = "log"
+)
+
+// defaultSnooze is how long "termidoro snooze" silences notifications.
+const defaultSnooze = time.Hour
+
+// IsControlCommand reports whether command talks to a running timer.
+func IsControlCommand(command string) bool {
+	switch command {
+	case CommandStatus, CommandPause, CommandResume, CommandToggle, CommandSkip, CommandExtend, CommandStop:
+		return true
+	}
+	return false
 }
 
 type Config struct {
+	Command string
+	// CommandArg is the optional positional argument of a subcommand, such
+	// as the duration given to extend.
+	CommandArg  string
+	CommandJSON bool
+	// CommandArgs are the arguments of the task and log subcommands, which
+	// parse them themselves.
+	CommandArgs []string
+	// StatusFormat is the preset or template given to status --format.
+	StatusFormat  string
 	WorkDuration  time.Duration
 	BreakDuration time.Duration
 	CustomName    string
 	AutoYes       bool
 	SoundEnabled  bool
+	// Task is the ID or name of the task to work on, from --task. Without
+	// one, an interactive run asks for a task.
+	Task string
+	// LongBreakDuration replaces every LongBreakEvery-th break. Long breaks
+	// are disabled when it is zero.
+	LongBreakDuration time.Duration
+	LongBreakEvery    int
+	// Journal asks what was done after every work session and saves the
+	// answer with it.
+	Journal bool
+	// Intentions asks for an intention before every work session and
+	// whether it was achieved afterwards.
+	Intentions bool
+	// VoidAfter voids a work session that is interrupted more than
+	// VoidAfter times. Zero never voids a session.
+	VoidAfter int
+	// Output is OutputText for the terminal UI or OutputJSON for a
+	// newline-delimited JSON event stream on stdout.
+	Output string
+	// JSONInterval throttles tick events in JSON mode.
+	JSONInterval time.Duration
+	// Hooks maps lifecycle events to the shell commands run for them.
+	Hooks map[string][]string
+	// Webhooks receive a POST for every session start, completion and
+	// cancellation.
+	Webhooks []*notify.Webhook
+	// DesktopEnabled and BellEnabled switch the desktop notification and
+	// terminal bell backends on or off.
+	DesktopEnabled bool
+	BellEnabled    bool
+	// DesktopActions waits for an answer after every session and offers
+	// it as buttons on the desktop notification.
+	DesktopActions bool
+	// TerminalEnabled sends notifications as terminal escape sequences
+	// through Terminal. It is on by default over SSH and inside tmux.
+	TerminalEnabled bool
+	Terminal        notify.Terminal
+	// SoundPlayers is the order in which Linux audio players are tried.
+	SoundPlayers []string
+	// Sounds overrides the file and volume per sound event.
+	Sounds map[string]notify.SoundSetting
+	// Tick ticks through work sessions, and breaks too with TickBreaks.
+	Tick       bool
+	TickBreaks bool
+	// Countdown beeps through the last Countdown seconds of a session.
+	Countdown int
+	// Alerts are the pre-end warnings and milestones of each session.
+	Alerts Alerts
+	// Texts templates the notification titles and bodies; nil keeps the
+	// defaults.
+	Texts *notify.Texts
+	// Policy holds notifications back during quiet hours, a snooze or a
+	// restricted mode.
+	Policy *notify.Policy
 }
 
 func parseDuration(durationStr string) (time.Duration, error) {
@@ -64,13 +187,17 @@ func parseDuration(durationStr string) (time.Duration, error) {
 	return 0, fmt.Errorf("invalid duration format")
 }
 
-func getTemplateSuggestion(input string) string {
-	templateNames := make([]string, 0, len(templates))
+func templateNames() []string {
+	names := make([]string, 0, len(templates))
 	for name := range templates {
-		templateNames = append(templateNames, name)
+		names = append(names, name)
 	}
+	sort.Strings(names)
+	return names
+}
 
-	matches := fuzzy.Find(input, templateNames)
+func getTemplateSuggestion(input string) string {
+	matches := fuzzy.Find(input, templateNames())
 	if len(matches) > 0 {
 		return matches[0].Str
 	}
@@ -87,14 +214,10 @@ func printTemplateError(templateName string) {
 
 	fmt.Println("Available templates:")
 	fmt.Println()
-	fmt.Println("  Name        Work      Break")
-	fmt.Println("  ─────────────────────────────────────")
+	fmt.Println("  Name        Work      Break     Long break")
+	fmt.Println("  ─────────────────────────────────────────────────")
 
-	names := make([]string, 0, len(templates))
-	for name := range templates {
-		names = append(names, name)
-	}
-	for _, name := range names {
+	for _, name := range templateNames() {
 		t := templates[name]
 		workMin := int(t.WorkDuration.Minutes())
 		breakMin := int(t.BreakDuration.Minutes())
@@ -102,7 +225,11 @@ func printTemplateError(templateName string) {
 		if name == suggestion {
 			nameStr = name + "  ←"
 		}
-		fmt.Printf("  %-10s  %dm       %dm       (%s)\n", nameStr, workMin, breakMin, t.Name)
+		longBreak := "-"
+		if t.LongBreakDuration > 0 {
+			longBreak = fmt.Sprintf("%dm/%d", int(t.LongBreakDuration.Minutes()), t.LongBreakEvery)
+		}
+		fmt.Printf("  %-10s  %-8s  %-8s  %-10s  (%s)\n", nameStr, fmt.Sprintf("%dm", workMin), fmt.Sprintf("%dm", breakMin), longBreak, t.Name)
 	}
 	fmt.Println()
 	fmt.Println("Use -T to list all templates.")
@@ -125,17 +252,113 @@ func printDurationError(durationStr string, flagName string) {
 func listTemplates() {
 	fmt.Println("Available templates:")
 	fmt.Println()
-	for name, t := range templates {
+	for _, name := range templateNames() {
+		t := templates[name]
 		workMin := int(t.WorkDuration.Minutes())
 		breakMin := int(t.BreakDuration.Minutes())
-		fmt.Printf("  %-12s  %dm work, %dm break  (%s)\n", name, workMin, breakMin, t.Name)
+		longBreak := ""
+		if t.LongBreakDuration > 0 {
+			longBreak = fmt.Sprintf(", %dm long break every %d", int(t.LongBreakDuration.Minutes()), t.LongBreakEvery)
+		}
+		fmt.Printf("  %-12s  %dm work, %dm break%s  (%s)\n", name, workMin, breakMin, longBreak, t.Name)
 	}
 	fmt.Println()
 	fmt.Println("Usage: termidoro -t <template>")
 	fmt.Println("Example: termidoro -t deep-work")
 }
 
+// loadConfigFile reads the config file named by --config, or the default
+// one. Only an explicitly requested file has to exist.
+func loadConfigFile(path string) *fileConfig {
+	if path == "" {
+		defaultPath, err := DefaultFilePath()
+		if err != nil {
+			return &fileConfig{}
+		}
+		path = defaultPath
+	} else if _, err := os.Stat(path); err != nil {
+		fmt.Printf("Error: Cannot read config file: %v\n", err)
+		os.Exit(1)
+	}
+
+	file, err := loadFile(path)
+	if err != nil {
+		fmt.Printf("Error: Invalid config file %s: %v\n", path, err)
+		os.Exit(1)
+	}
+	return file
+}
+
+func parseCommand(name string, args []string) (*Config, bool) {
+	cfg := &Config{Command: name}
+	fs := flag.NewFlagSet("termidoro "+name, flag.ExitOnError)
+	if IsControlCommand(name) {
+		fs.BoolVar(&cfg.CommandJSON, "json", false, "Print the timer status as JSON")
+	}
+	if name == CommandStatus {
+		fs.StringVar(&cfg.StatusFormat, "format", "", "Status line preset (default, tmux, polybar, i3blocks, waybar) or Go template")
+	}
+
+	// "extend -5m" shortens the session; keep it from being read as a flag.
+	var positional []string
+	var flags []string
+	for _, arg := range args {
+		if name == CommandExtend && len(arg) > 1 && arg[0] == '-' && arg[1] >= '0' && arg[1] <= '9' {
+			positional = append(positional, arg)
+		} else {
+			flags = append(flags, arg)
+		}
+	}
+	fs.Parse(flags)
+	positional = append(positional, fs.Args()...)
+
+	maxArgs := 0
+	if name == CommandExtend || name == CommandSnooze {
+		maxArgs = 1
+	}
+	if len(positional) > maxArgs {
+		fmt.Printf("Error: Unexpected argument '%s' for '%s'\n", positional[maxArgs], name)
+		os.Exit(1)
+	}
+
+	if name == CommandExtend && len(positional) == 1 {
+		arg := positional[0]
+		sign := ""
+		if strings.HasPrefix(arg, "-") || strings.HasPrefix(arg, "+") {
+			sign, arg = strings.TrimPrefix(arg[:1], "+"), arg[1:]
+		}
+		duration, err := parseDuration(arg)
+		if err != nil {
+			printDurationError(positional[0], "extend")
+		}
+		cfg.CommandArg = sign + duration.String()
+	}
+
+	if name == CommandSnooze {
+		cfg.CommandArg = defaultSnooze.String()
+		if len(positional) == 1 && positional[0] == "off" {
+			cfg.CommandArg = "off"
+		} else if len(positional) == 1 {
+			duration, err := parseDuration(positional[0])
+			if err != nil || duration <= 0 {
+				printDurationError(positional[0], "snooze")
+			}
+			cfg.CommandArg = duration.String()
+		}
+	}
+	return cfg, false
+}
+
 func Parse() (*Config, bool) {
+	if len(os.Args) > 1 {
+		if os.Args[1] == CommandTask || os.Args[1] == CommandLog {
+			return &Config{Command: os.Args[1], CommandArgs: os.Args[2:]}, false
+		}
+		if os.Args[1] == CommandStats || os.Args[1] == CommandSnooze || IsControlCommand(os.Args[1]) {
+			return parseCommand(os.Args[1], os.Args[2:])
+		}
+	}
+
 	flag.IntVar(&minutesFlag, "m", 25, "Default work duration in minutes")
 	flag.BoolVar(&autoYesFlag, "y", false, "Auto-confirm prompts (for scripting)")
 	flag.BoolVar(&noSoundFlag, "no-sound", false, "Disable sound notifications")
@@ -143,22 +366,131 @@ func Parse() (*Config, bool) {
 	flag.StringVar(&workFlag, "w", "", "Work duration (short form)")
 	flag.StringVar(&breakFlag, "break", "", "Break duration (e.g., 1m, 10m, 30s)")
 	flag.StringVar(&breakFlag, "b", "", "Break duration (short form)")
+	flag.StringVar(&longBreakFlag, "long-break", "", "Long break duration (e.g., 15m, 30m); enables long breaks")
+	flag.IntVar(&longBreakEvery, "long-break-every", 0, "Take a long break after every N work sessions (default 4)")
 	flag.StringVar(&nameFlag, "name", "", "Custom name for work sessions")
 	flag.StringVar(&nameFlag, "n", "", "Custom name for work sessions (short form)")
-	flag.StringVar(&templateFlag, "template", "", "Use a preset template (deep-work, sprint, focus, study)")
+	flag.StringVar(&taskFlag, "task", "", "ID or name of the task to work on")
+	flag.BoolVar(&journalFlag, "journal", false, "Ask what you got done after every work session")
+	flag.BoolVar(&intentionsFlag, "intentions", false, "Set an intention before every work session and review it afterwards")
+	flag.IntVar(&voidAfterFlag, "void-after", 0, "Void a work session after more than N interruptions (0 never voids)")
+	flag.StringVar(&templateFlag, "template", "", "Use a preset or user template (deep-work, sprint, focus, study, ...)")
 	flag.StringVar(&templateFlag, "t", "", "Use a preset template (short form)")
 	flag.BoolVar(&listTemplatesFlag, "templates", false, "List available templates")
 	flag.BoolVar(&listTemplatesFlag, "T", false, "List available templates (short form)")
+	flag.StringVar(&outputFlag, "output", OutputText, "Output mode: text or json (newline-delimited events, implies -y)")
+	flag.StringVar(&jsonIntervalFlag, "json-interval", "1s", "Interval between tick events in json output mode")
+	flag.BoolVar(&noHooksFlag, "no-hooks", false, "Do not run the hooks from the config file")
+	flag.BoolVar(&noDesktopFlag, "no-desktop", false, "Disable desktop notifications")
+	flag.BoolVar(&bellFlag, "bell", false, "Ring the terminal bell when a session completes")
+	flag.BoolVar(&actionsFlag, "notify-actions", false, "Wait after every session and answer from buttons on the desktop notification")
+	flag.BoolVar(&terminalFlag, "terminal-notify", false, "Notify through the terminal with OSC escape sequences (default on over SSH and in tmux)")
+	registerSoundFlags()
+	registerAlertFlags()
+	flag.StringVar(&notifyModeFlag, "notify-mode", string(notify.ModeAll), "Notifications to allow: all, sound-only, visual-only or silent")
+	flag.StringVar(&configFlag, "config", "", "Path to the config file (default $XDG_CONFIG_HOME/termidoro/config.toml)")
 	flag.Parse()
 
+	setFlags := map[string]bool{}
+	flag.Visit(func(f *flag.Flag) {
+		setFlags[f.Name] = true
+	})
+
+	file := loadConfigFile(configFlag)
+	if err := mergeTemplates(templates, file.Templates); err != nil {
+		fmt.Printf("Error: Invalid config file: %v\n", err)
+		os.Exit(1)
+	}
+
 	if listTemplatesFlag {
 		listTemplates()
 		return nil, true
 	}
 
 	cfg := &Config{
-		AutoYes:      autoYesFlag,
-		SoundEnabled: !noSoundFlag,
+		AutoYes:        autoYesFlag,
+		SoundEnabled:   !noSoundFlag,
+		DesktopEnabled: !noDesktopFlag,
+		BellEnabled:    bellFlag,
+		DesktopActions: actionsFlag,
+		Task:           taskFlag,
+		Journal:        journalFlag,
+		Intentions:     intentionsFlag,
+	}
+	if file.Intentions != nil && !setFlags["intentions"] {
+		cfg.Intentions = *file.Intentions
+	}
+	if file.Journal != nil && !setFlags["journal"] {
+		cfg.Journal = *file.Journal
+	}
+	if file.AutoYes != nil && !setFlags["y"] {
+		cfg.AutoYes = *file.AutoYes
+	}
+	cfg.VoidAfter = file.VoidAfter
+	if setFlags["void-after"] {
+		if voidAfterFlag < 0 {
+			fmt.Println("Error: --void-after must be a positive number")
+			os.Exit(1)
+		}
+		cfg.VoidAfter = voidAfterFlag
+	}
+	if file.Sound != nil && !setFlags["no-sound"] {
+		cfg.SoundEnabled = *file.Sound
+	}
+	if file.Notify.Desktop.Enabled != nil && !setFlags["no-desktop"] {
+		cfg.DesktopEnabled = *file.Notify.Desktop.Enabled
+	}
+	if file.Notify.Desktop.Actions != nil && !setFlags["notify-actions"] {
+		cfg.DesktopActions = *file.Notify.Desktop.Actions
+	}
+	if file.Notify.Bell.Enabled != nil && !setFlags["bell"] {
+		cfg.BellEnabled = *file.Notify.Bell.Enabled
+	}
+	if err := applyTerminal(cfg, file.Notify, setFlags, os.Getenv); err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(1)
+	}
+	applyOutputFlags(cfg)
+	if !noHooksFlag && len(file.Hooks) > 0 {
+		cfg.Hooks = make(map[string][]string, len(file.Hooks))
+		for event, commands := range file.Hooks {
+			cfg.Hooks[event] = commands
+		}
+	}
+	cfg.Webhooks = file.webhooks
+	cfg.SoundPlayers = file.Notify.Sound.Players
+	sounds, err := resolveSounds(file.Notify.Sound, setFlags)
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(1)
+	}
+	cfg.Sounds = sounds
+	applyTickFlags(cfg, file.Notify.Sound, setFlags)
+	alerts, err := resolveAlerts(file.Alerts, cfg.Sounds)
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(1)
+	}
+	cfg.Alerts = alerts
+	texts, err := resolveTexts(file, templateFlag)
+	if err != nil {
+		fmt.Printf("Error: Invalid notification text: %v\n", err)
+		os.Exit(1)
+	}
+	cfg.Texts = texts
+	policy, err := resolvePolicy(file.Notify.Policy, setFlags)
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(1)
+	}
+	cfg.Policy = policy
+	// Alerts sent to the bell need it on, unless it was turned off.
+	if slices.Contains(alerts.Via, "bell") && file.Notify.Bell.Enabled == nil {
+		cfg.BellEnabled = true
+	}
+	if !slices.Equal(alerts.Via, defaultAlertVia) && slices.Contains(alerts.Via, "terminal") &&
+		file.Notify.Terminal.Enabled == nil && !setFlags["terminal-notify"] {
+		cfg.TerminalEnabled = true
 	}
 
 	if templateFlag != "" {
@@ -170,6 +502,12 @@ func Parse() (*Config, bool) {
 		cfg.WorkDuration = template.WorkDuration
 		cfg.BreakDuration = template.BreakDuration
 		cfg.CustomName = template.Name
+		cfg.LongBreakDuration = template.LongBreakDuration
+		cfg.LongBreakEvery = template.LongBreakEvery
+		if cfg.LongBreakDuration == 0 {
+			cfg.LongBreakDuration = file.longBreakDuration
+			cfg.LongBreakEvery = file.LongBreakEvery
+		}
 
 		args := flag.Args()
 		if len(args) > 0 {
@@ -177,6 +515,7 @@ func Parse() (*Config, bool) {
 			fmt.Println("Use -T to list available templates.")
 			os.Exit(1)
 		}
+		applyLongBreakFlags(cfg)
 		return cfg, false
 	}
 
@@ -202,6 +541,8 @@ func Parse() (*Config, bool) {
 		} else {
 			cfg.WorkDuration = duration
 		}
+	} else if file.workDuration > 0 && !setFlags["m"] {
+		cfg.WorkDuration = file.workDuration
 	} else {
 		cfg.WorkDuration = time.Duration(minutesFlag) * time.Minute
 	}
@@ -226,6 +567,8 @@ func Parse() (*Config, bool) {
 		} else {
 			cfg.BreakDuration = duration
 		}
+	} else if file.breakDuration > 0 {
+		cfg.BreakDuration = file.breakDuration
 	} else {
 		cfg.BreakDuration = 5 * time.Minute
 	}
@@ -236,5 +579,59 @@ func Parse() (*Config, bool) {
 		cfg.CustomName = args[2]
 	}
 
+	cfg.LongBreakDuration = file.longBreakDuration
+	cfg.LongBreakEvery = file.LongBreakEvery
+	if cfg.LongBreakEvery > 0 && cfg.LongBreakDuration == 0 {
+		cfg.LongBreakDuration = defaultLongBreakDuration
+	}
+	applyLongBreakFlags(cfg)
 	return cfg, false
 }
+
+func applyOutputFlags(cfg *Config) {
+	switch strings.ToLower(outputFlag) {
+	case OutputText:
+		cfg.Output = OutputText
+	case OutputJSON:
+		// There is nobody to answer prompts in a JSON stream.
+		cfg.Output = OutputJSON
+		cfg.AutoYes = true
+	default:
+		fmt.Printf("Error: Unknown output mode '%s' (expected text or json)\n", outputFlag)
+		os.Exit(1)
+	}
+
+	interval, err := parseDuration(jsonIntervalFlag)
+	if err != nil || interval < time.Second {
+		if err == nil {
+			fmt.Println("Error: --json-interval must be at least 1s")
+			os.Exit(1)
+		}
+		printDurationError(jsonIntervalFlag, "json-interval")
+	}
+	cfg.JSONInterval = interval
+}
+
+func applyLongBreakFlags(cfg *Config) {
+	if longBreakFlag != "" {
+		duration, err := parseDuration(longBreakFlag)
+		if err != nil {
+			printDurationError(longBreakFlag, "long-break")
+		}
+		cfg.LongBreakDuration = duration
+	}
+
+	if longBreakEvery < 0 {
+		fmt.Println("Error: --long-break-every must be a positive number")
+		os.Exit(1)
+	}
+	if longBreakEvery > 0 {
+		cfg.LongBreakEvery = longBreakEvery
+		if cfg.LongBreakDuration == 0 {
+			cfg.LongBreakDuration = defaultLongBreakDuration
+		}
+	}
+	if cfg.LongBreakEvery == 0 {
+		cfg.LongBreakEvery = defaultLongBreakEvery
+	}
+}
@@ -1,8 +1,12 @@
 package config
 
 import (
+	"os"
+	"path/filepath"
 	"testing"
 	"time"
+
+	"termidoro/notify"
 )
 
 func TestParseDuration(t *testing.T) {
@@ -62,3 +66,308 @@ func TestParseDuration(t *testing.T) {
 		})
 	}
 }
+
+func TestLoadFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.toml")
+	content := `
+work = "30m"
+break = "6m"
+sound = false
+journal = true
+void_after = 3
+
+[hooks]
+work_start = "dnd on"
+break_start = ["playerctl pause", "lock"]
+
+[[webhooks]]
+url = "https://example.com/hook"
+timeout = "2s"
+retries = 1
+events = ["session_completed"]
+
+[webhooks.headers]
+Authorization = "Bearer token"
+
+[templates.writing]
+name = "Writing"
+work = "40m"
+break = "8m"
+
+[templates.focus]
+work = "20m"
+`
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	fc, err := loadFile(path)
+	if err != nil {
+		t.Fatalf("loadFile: %v", err)
+	}
+	if fc.workDuration != 30*time.Minute || fc.breakDuration != 6*time.Minute {
+		t.Errorf("Unexpected durations: %v, %v", fc.workDuration, fc.breakDuration)
+	}
+	if fc.Sound == nil || *fc.Sound {
+		t.Error("Expected sound to be disabled")
+	}
+	if fc.Journal == nil || !*fc.Journal {
+		t.Error("Expected the journal to be enabled")
+	}
+	if fc.VoidAfter != 3 {
+		t.Errorf("Expected void_after 3, got %d", fc.VoidAfter)
+	}
+	if len(fc.Hooks["work_start"]) != 1 || len(fc.Hooks["break_start"]) != 2 {
+		t.Errorf("Unexpected hooks: %v", fc.Hooks)
+	}
+	if len(fc.webhooks) != 1 {
+		t.Fatalf("Expected 1 webhook, got %d", len(fc.webhooks))
+	}
+	if w := fc.webhooks[0]; w.Timeout != 2*time.Second || w.Retries != 1 || w.Headers["Authorization"] != "Bearer token" {
+		t.Errorf("Unexpected webhook: %+v", w)
+	}
+
+	merged := map[string]Template{
+		"focus": {25 * time.Minute, 5 * time.Minute, "Focus", 15 * time.Minute, 4},
+	}
+	if err := mergeTemplates(merged, fc.Templates); err != nil {
+		t.Fatalf("mergeTemplates: %v", err)
+	}
+	if got := merged["writing"]; got.WorkDuration != 40*time.Minute || got.BreakDuration != 8*time.Minute || got.Name != "Writing" {
+		t.Errorf("Unexpected user template: %+v", got)
+	}
+	if got := merged["focus"]; got.WorkDuration != 20*time.Minute || got.BreakDuration != 5*time.Minute || got.LongBreakDuration != 15*time.Minute {
+		t.Errorf("Expected focus override to keep built-in fields, got %+v", got)
+	}
+}
+
+func TestLoadFileErrors(t *testing.T) {
+	fc, err := loadFile(filepath.Join(t.TempDir(), "missing.toml"))
+	if err != nil || fc == nil {
+		t.Fatalf("Expected empty config for missing file, got %v", err)
+	}
+
+	path := filepath.Join(t.TempDir(), "config.toml")
+	if err := os.WriteFile(path, []byte(`work = "abc"`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := loadFile(path); err == nil {
+		t.Error("Expected error for invalid duration")
+	}
+
+	if err := os.WriteFile(path, []byte("[hooks]\nlunch = \"eat\""), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := loadFile(path); err == nil {
+		t.Error("Expected error for unknown hook event")
+	}
+
+	if err := os.WriteFile(path, []byte("[[webhooks]]\nurl = \"example.com\""), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := loadFile(path); err == nil {
+		t.Error("Expected error for webhook without scheme")
+	}
+
+	if err := os.WriteFile(path, []byte("[notify.sound]\nplayers = [\"aplay\", \"vlc\"]"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := loadFile(path); err == nil {
+		t.Error("Expected error for unknown audio player")
+	}
+
+	if err := os.WriteFile(path, []byte("void_after = -1"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := loadFile(path); err == nil {
+		t.Error("Expected error for negative void_after")
+	}
+
+	err = mergeTemplates(map[string]Template{}, map[string]fileTemplate{"empty": {Name: "Empty"}})
+	if err == nil {
+		t.Error("Expected error for template without work duration")
+	}
+}
+
+func TestResolveSounds(t *testing.T) {
+	dir := t.TempDir()
+	bell := filepath.Join(dir, "bell.wav")
+	wav := []byte("RIFF\x24\x00\x00\x00WAVEfmt ")
+	if err := os.WriteFile(bell, wav, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	percent := func(n int) *int { return &n }
+
+	fs := fileSound{
+		Volume:        percent(50),
+		BreakComplete: fileSoundEvent{File: bell, Volume: percent(80)},
+	}
+	sounds, err := resolveSounds(fs, map[string]bool{})
+	if err != nil {
+		t.Fatalf("resolveSounds: %v", err)
+	}
+	if got := sounds[notify.SoundBreakComplete]; got.File != bell || got.Volume != 0.8 {
+		t.Errorf("Unexpected break sound: %+v", got)
+	}
+	if got := sounds[notify.SoundWorkComplete]; got.File != "" || got.Volume != 0.5 {
+		t.Errorf("Expected the default volume for work, got %+v", got)
+	}
+	if got := sounds[notify.SoundLongBreakComplete]; got.File != bell {
+		t.Errorf("Expected long break to inherit the break file, got %+v", got)
+	}
+
+	volumeFlag = 30
+	defer func() { volumeFlag = 100 }()
+	sounds, err = resolveSounds(fs, map[string]bool{"volume": true})
+	if err != nil {
+		t.Fatalf("resolveSounds: %v", err)
+	}
+	if got := sounds[notify.SoundBreakComplete]; got.Volume != 0.3 {
+		t.Errorf("Expected --volume to take precedence, got %+v", got)
+	}
+
+	if _, err := resolveSounds(fileSound{Volume: percent(150)}, map[string]bool{}); err == nil {
+		t.Error("Expected error for volume above 100")
+	}
+	missing := fileSound{Warning: fileSoundEvent{File: filepath.Join(dir, "missing.ogg")}}
+	if _, err := resolveSounds(missing, map[string]bool{}); err == nil {
+		t.Error("Expected error for missing sound file")
+	}
+}
+
+func TestResolveAlerts(t *testing.T) {
+	alerts, err := resolveAlerts(fileAlerts{BeforeEnd: []string{"5m", "2m"}, Milestones: []int{50}}, nil)
+	if err != nil {
+		t.Fatalf("resolveAlerts: %v", err)
+	}
+	if len(alerts.BeforeEnd) != 2 || alerts.BeforeEnd[1] != 2*time.Minute || len(alerts.Milestones) != 1 {
+		t.Errorf("Unexpected alerts: %+v", alerts)
+	}
+	if len(alerts.Via) != 3 || alerts.Via[0] != "desktop" {
+		t.Errorf("Expected the default channels, got %v", alerts.Via)
+	}
+
+	warning := map[string]notify.SoundSetting{notify.SoundWarning: {File: "tick.wav", Volume: 1}}
+	alerts, err = resolveAlerts(fileAlerts{}, warning)
+	if err != nil {
+		t.Fatalf("resolveAlerts: %v", err)
+	}
+	if len(alerts.BeforeEnd) != 1 || alerts.BeforeEnd[0] != time.Minute || len(alerts.Via) != 1 || alerts.Via[0] != "sound" {
+		t.Errorf("Expected a one-minute sound warning, got %+v", alerts)
+	}
+
+	for _, fa := range []fileAlerts{
+		{BeforeEnd: []string{"soon"}},
+		{Milestones: []int{100}},
+		{Via: []string{"pager"}},
+	} {
+		if _, err := resolveAlerts(fa, nil); err == nil {
+			t.Errorf("Expected error for %+v", fa)
+		}
+	}
+}
+
+func TestResolveTexts(t *testing.T) {
+	file := &fileConfig{
+		Notify: fileNotify{Text: map[string]fileText{
+			"work_complete": {Title: "Done", Body: "Take {{.NextDuration}}"},
+		}},
+		Templates: map[string]fileTemplate{
+			"Writing": {Text: map[string]fileText{"work_complete": {Title: "{{.Name}} done"}}},
+		},
+	}
+	texts, err := resolveTexts(file, "writing")
+	if err != nil || texts == nil {
+		t.Fatalf("resolveTexts: %v", err)
+	}
+	if texts, err := resolveTexts(&fileConfig{}, ""); err != nil || texts != nil {
+		t.Errorf("Expected no texts without configuration, got %v, %v", texts, err)
+	}
+
+	path := filepath.Join(t.TempDir(), "config.toml")
+	content := "[templates.writing]\nwork = \"40m\"\n[templates.writing.text.work_complete]\ntitle = \"{{.Nope}}\"\n"
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := loadFile(path); err == nil {
+		t.Error("Expected error for a template text with an unknown field")
+	}
+}
+
+func TestResolvePolicy(t *testing.T) {
+	fp := filePolicy{Mode: "visual-only", QuietHours: []string{"22:00-08:00"}, QuietMode: "sound-only"}
+	policy, err := resolvePolicy(fp, map[string]bool{})
+	if err != nil {
+		t.Fatalf("resolvePolicy: %v", err)
+	}
+	if policy.Mode != notify.ModeVisualOnly || policy.QuietMode != notify.ModeSoundOnly || len(policy.QuietHours) != 1 {
+		t.Errorf("Unexpected policy: %+v", policy)
+	}
+
+	notifyModeFlag = "silent"
+	defer func() { notifyModeFlag = string(notify.ModeAll) }()
+	if policy, err = resolvePolicy(fp, map[string]bool{"notify-mode": true}); err != nil || policy.Mode != notify.ModeSilent {
+		t.Errorf("Expected --notify-mode to take precedence, got %+v, %v", policy, err)
+	}
+
+	for _, fp := range []filePolicy{
+		{Mode: "loud"},
+		{QuietMode: "whisper"},
+		{QuietHours: []string{"22-08"}},
+	} {
+		if _, err := resolvePolicy(fp, map[string]bool{}); err == nil {
+			t.Errorf("Expected error for %+v", fp)
+		}
+	}
+}
+
+func TestApplyTerminal(t *testing.T) {
+	ssh := func(name string) string {
+		if name == "SSH_TTY" {
+			return "/dev/pts/3"
+		}
+		return ""
+	}
+	local := func(string) string { return "" }
+
+	cfg := &Config{DesktopEnabled: true}
+	if err := applyTerminal(cfg, fileNotify{}, map[string]bool{}, ssh); err != nil {
+		t.Fatalf("applyTerminal: %v", err)
+	}
+	if !cfg.TerminalEnabled || cfg.DesktopEnabled {
+		t.Errorf("Expected the terminal to replace the desktop over SSH, got %+v", cfg)
+	}
+
+	enabled := true
+	cfg = &Config{DesktopEnabled: true}
+	fn := fileNotify{Desktop: fileDesktop{Enabled: &enabled}, Terminal: fileTerminal{Protocol: "osc777"}}
+	if err := applyTerminal(cfg, fn, map[string]bool{}, ssh); err != nil {
+		t.Fatalf("applyTerminal: %v", err)
+	}
+	if !cfg.DesktopEnabled || cfg.Terminal.Protocol != notify.ProtocolOSC777 {
+		t.Errorf("Expected the configured desktop and protocol to stay, got %+v", cfg)
+	}
+
+	tmux := func(name string) string {
+		if name == "TMUX" {
+			return "/tmp/tmux-1000/default,1234,0"
+		}
+		return ""
+	}
+	cfg = &Config{DesktopEnabled: true}
+	if err := applyTerminal(cfg, fileNotify{}, map[string]bool{}, tmux); err != nil {
+		t.Fatalf("applyTerminal: %v", err)
+	}
+	if !cfg.TerminalEnabled || !cfg.DesktopEnabled || !cfg.Terminal.Tmux {
+		t.Errorf("Expected the terminal next to the desktop in a local tmux, got %+v", cfg)
+	}
+
+	cfg = &Config{DesktopEnabled: true}
+	if err := applyTerminal(cfg, fileNotify{}, map[string]bool{}, local); err != nil || cfg.TerminalEnabled {
+		t.Errorf("Expected no terminal notifications locally, got %v, %v", cfg.TerminalEnabled, err)
+	}
+
+	if err := applyTerminal(&Config{}, fileNotify{Terminal: fileTerminal{Protocol: "osc99"}}, map[string]bool{}, local); err == nil {
+		t.Error("Expected error for unknown protocol")
+	}
+}
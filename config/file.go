@@ -0,0 +1,290 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/toml"
+
+	"termidoro/hooks"
+	"termidoro/notify"
+)
+
+// fileConfig mirrors config.toml. Durations use the same formats as the
+// command line flags.
+type fileConfig struct {
+	Work           string                  `toml:"work"`
+	Break          string                  `toml:"break"`
+	LongBreak      string                  `toml:"long_break"`
+	LongBreakEvery int                     `toml:"long_break_every"`
+	VoidAfter      int                     `toml:"void_after"`
+	Sound          *bool                   `toml:"sound"`
+	AutoYes        *bool                   `toml:"auto_yes"`
+	Journal        *bool                   `toml:"journal"`
+	Intentions     *bool                   `toml:"intentions"`
+	Templates      map[string]fileTemplate `toml:"templates"`
+	Hooks          map[string]commandList  `toml:"hooks"`
+	Webhooks       []fileWebhook           `toml:"webhooks"`
+	Notify         fileNotify              `toml:"notify"`
+	Alerts         fileAlerts              `toml:"alerts"`
+
+	// Parsed forms of the duration fields; zero when unset.
+	workDuration      time.Duration
+	breakDuration     time.Duration
+	longBreakDuration time.Duration
+	webhooks          []*notify.Webhook
+}
+
+type fileTemplate struct {
+	Name           string              `toml:"name"`
+	Work           string              `toml:"work"`
+	Break          string              `toml:"break"`
+	LongBreak      string              `toml:"long_break"`
+	LongBreakEvery int                 `toml:"long_break_every"`
+	Text           map[string]fileText `toml:"text"`
+}
+
+type fileAlerts struct {
+	BeforeEnd  []string `toml:"before_end"`
+	Milestones []int    `toml:"milestones"`
+	Via        []string `toml:"via"`
+	Breaks     bool     `toml:"breaks"`
+}
+
+// fileNotify holds one table per notification backend.
+type fileNotify struct {
+	Sound    fileSound           `toml:"sound"`
+	Desktop  fileDesktop         `toml:"desktop"`
+	Bell     fileBackend         `toml:"bell"`
+	Terminal fileTerminal        `toml:"terminal"`
+	Text     map[string]fileText `toml:"text"`
+	Policy   filePolicy          `toml:"policy"`
+}
+
+type fileDesktop struct {
+	Enabled *bool `toml:"enabled"`
+	Actions *bool `toml:"actions"`
+}
+
+type fileTerminal struct {
+	Enabled  *bool  `toml:"enabled"`
+	Protocol string `toml:"protocol"`
+	Progress *bool  `toml:"progress"`
+}
+
+type filePolicy struct {
+	Mode       string   `toml:"mode"`
+	QuietHours []string `toml:"quiet_hours"`
+	QuietMode  string   `toml:"quiet_mode"`
+}
+
+type fileSound struct {
+	// Players is the order in which Linux audio players are tried.
+	Players []string `toml:"players"`
+	// Volume is the default volume in percent for every sound event.
+	Volume            *int           `toml:"volume"`
+	WorkComplete      fileSoundEvent `toml:"work_complete"`
+	BreakComplete     fileSoundEvent `toml:"break_complete"`
+	LongBreakComplete fileSoundEvent `toml:"long_break_complete"`
+	Warning           fileSoundEvent `toml:"warning"`
+	Tick              fileSoundEvent `toml:"tick"`
+	Countdown         fileSoundEvent `toml:"countdown"`
+	// Ticking, TickBreaks and CountdownSecs mirror --tick, --tick-breaks
+	// and --countdown.
+	Ticking       *bool `toml:"ticking"`
+	TickBreaks    *bool `toml:"tick_breaks"`
+	CountdownSecs *int  `toml:"countdown_secs"`
+}
+
+type fileSoundEvent struct {
+	File   string `toml:"file"`
+	Volume *int   `toml:"volume"`
+}
+
+type fileBackend struct {
+	Enabled *bool `toml:"enabled"`
+}
+
+type fileWebhook struct {
+	URL     string            `toml:"url"`
+	Body    string            `toml:"body"`
+	Headers map[string]string `toml:"headers"`
+	Timeout string            `toml:"timeout"`
+	Retries *int              `toml:"retries"`
+	Events  []string          `toml:"events"`
+}
+
+// commandList accepts either a single command string or an array of them.
+type commandList []string
+
+func (c *commandList) UnmarshalTOML(value any) error {
+	switch v := value.(type) {
+	case string:
+		*c = commandList{v}
+	case []any:
+		for _, item := range v {
+			command, ok := item.(string)
+			if !ok {
+				return fmt.Errorf("hook commands must be strings")
+			}
+			*c = append(*c, command)
+		}
+	default:
+		return fmt.Errorf("hook must be a command or a list of commands")
+	}
+	return nil
+}
+
+// Dir returns the termidoro directory under $XDG_CONFIG_HOME, falling back
+// to ~/.config when the variable is unset.
+func Dir() (string, error) {
+	base := os.Getenv("XDG_CONFIG_HOME")
+	if base == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", err
+		}
+		base = filepath.Join(home, ".config")
+	}
+	return filepath.Join(base, "termidoro"), nil
+}
+
+// DefaultFilePath returns the location of config.toml.
+func DefaultFilePath() (string, error) {
+	dir, err := Dir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "config.toml"), nil
+}
+
+// loadFile reads the config file at path. A missing file yields an empty
+// configuration.
+func loadFile(path string) (*fileConfig, error) {
+	fc := &fileConfig{}
+	_, err := toml.DecodeFile(path, fc)
+	if errors.Is(err, os.ErrNotExist) {
+		return fc, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return fc, fc.parse()
+}
+
+func (fc *fileConfig) parse() error {
+	for _, field := range []struct {
+		key   string
+		value string
+		dest  *time.Duration
+	}{
+		{"work", fc.Work, &fc.workDuration},
+		{"break", fc.Break, &fc.breakDuration},
+		{"long_break", fc.LongBreak, &fc.longBreakDuration},
+	} {
+		duration, err := parseDuration(field.value)
+		if err != nil || duration < 0 {
+			return fmt.Errorf("invalid duration %q for %s", field.value, field.key)
+		}
+		*field.dest = duration
+	}
+	if fc.LongBreakEvery < 0 {
+		return fmt.Errorf("long_break_every must be a positive number")
+	}
+	if fc.VoidAfter < 0 {
+		return fmt.Errorf("void_after must be a positive number")
+	}
+	for event := range fc.Hooks {
+		if !hooks.IsEvent(event) {
+			return fmt.Errorf("unknown hook event %q (expected one of %s)", event, strings.Join(hooks.Events, ", "))
+		}
+	}
+	if cs := fc.Notify.Sound.CountdownSecs; cs != nil && *cs < 0 {
+		return fmt.Errorf("countdown_secs must be a positive number")
+	}
+	if _, err := notify.NewTexts(toTexts(fc.Notify.Text)); err != nil {
+		return fmt.Errorf("notification text: %w", err)
+	}
+	for name, ft := range fc.Templates {
+		if _, err := notify.NewTexts(toTexts(ft.Text)); err != nil {
+			return fmt.Errorf("template %s: notification text: %w", name, err)
+		}
+	}
+	for _, player := range fc.Notify.Sound.Players {
+		if !notify.IsPlayer(player) {
+			return fmt.Errorf("unknown audio player %q (expected one of %s)", player, notify.PlayerNames())
+		}
+	}
+	for i, fw := range fc.Webhooks {
+		timeout, err := parseDuration(fw.Timeout)
+		if err != nil || timeout < 0 {
+			return fmt.Errorf("webhook %d: invalid timeout %q", i+1, fw.Timeout)
+		}
+		retries := -1
+		if fw.Retries != nil {
+			retries = *fw.Retries
+		}
+		webhook, err := notify.NewWebhook(fw.URL, fw.Body, fw.Headers, timeout, retries, fw.Events)
+		if err != nil {
+			return fmt.Errorf("webhook %d: %w", i+1, err)
+		}
+		fc.webhooks = append(fc.webhooks, webhook)
+	}
+	return nil
+}
+
+// mergeTemplates adds the user templates to the built-in ones. A user
+// template with the same name as a built-in overrides only the fields it sets.
+func mergeTemplates(base map[string]Template, user map[string]fileTemplate) error {
+	for key, ft := range user {
+		key = strings.ToLower(key)
+		t := base[key]
+
+		if ft.Name != "" {
+			t.Name = ft.Name
+		} else if t.Name == "" {
+			t.Name = key
+		}
+		durations := []struct {
+			field string
+			value string
+			dest  *time.Duration
+		}{
+			{"work", ft.Work, &t.WorkDuration},
+			{"break", ft.Break, &t.BreakDuration},
+			{"long_break", ft.LongBreak, &t.LongBreakDuration},
+		}
+		for _, d := range durations {
+			if d.value == "" {
+				continue
+			}
+			duration, err := parseDuration(d.value)
+			if err != nil {
+				return fmt.Errorf("template %s: invalid duration %q for %s", key, d.value, d.field)
+			}
+			*d.dest = duration
+		}
+		if ft.LongBreakEvery < 0 {
+			return fmt.Errorf("template %s: long_break_every must be a positive number", key)
+		}
+		if ft.LongBreakEvery > 0 {
+			t.LongBreakEvery = ft.LongBreakEvery
+		}
+
+		if t.WorkDuration <= 0 {
+			return fmt.Errorf("template %s: work duration is required", key)
+		}
+		if t.BreakDuration <= 0 {
+			t.BreakDuration = 5 * time.Minute
+		}
+		if t.LongBreakDuration > 0 && t.LongBreakEvery == 0 {
+			t.LongBreakEvery = defaultLongBreakEvery
+		}
+		base[key] = t
+	}
+	return nil
+}
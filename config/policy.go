@@ -0,0 +1,43 @@
+package config
+
+import (
+	"fmt"
+	"os"
+
+	"termidoro/history"
+	"termidoro/notify"
+)
+
+// resolvePolicy builds the notification policy from --notify-mode and the
+// [notify.policy] table.
+func resolvePolicy(fp filePolicy, setFlags map[string]bool) (*notify.Policy, error) {
+	policy := &notify.Policy{Mode: notify.ModeAll, QuietMode: notify.ModeSilent}
+
+	mode := fp.Mode
+	if setFlags["notify-mode"] || mode == "" {
+		mode = notifyModeFlag
+	}
+	var err error
+	if policy.Mode, err = notify.ParseMode(mode); err != nil {
+		return nil, err
+	}
+	if fp.QuietMode != "" {
+		if policy.QuietMode, err = notify.ParseMode(fp.QuietMode); err != nil {
+			return nil, fmt.Errorf("quiet_mode: %w", err)
+		}
+	}
+	for _, window := range fp.QuietHours {
+		q, err := notify.ParseQuietHours(window)
+		if err != nil {
+			return nil, err
+		}
+		policy.QuietHours = append(policy.QuietHours, q)
+	}
+
+	if path, err := history.SnoozePath(); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: Snooze disabled: %v\n", err)
+	} else {
+		policy.SnoozePath = path
+	}
+	return policy, nil
+}
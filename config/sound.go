@@ -0,0 +1,141 @@
+package config
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"termidoro/notify"
+)
+
+// soundFlags holds the per-event sound file flags, keyed by sound event.
+var soundFlags = map[string]*string{
+	notify.SoundWorkComplete:      new(string),
+	notify.SoundBreakComplete:     new(string),
+	notify.SoundLongBreakComplete: new(string),
+	notify.SoundWarning:           new(string),
+}
+
+var (
+	volumeFlag     int
+	tickFlag       bool
+	tickBreaksFlag bool
+	countdownFlag  int
+)
+
+func registerSoundFlags() {
+	flag.StringVar(soundFlags[notify.SoundWorkComplete], "work-sound", "", "Sound file (WAV, OGG, AIFF) played when a work session completes")
+	flag.StringVar(soundFlags[notify.SoundBreakComplete], "break-sound", "", "Sound file played when a break completes")
+	flag.StringVar(soundFlags[notify.SoundLongBreakComplete], "long-break-sound", "", "Sound file played when a long break completes (default: the break sound)")
+	flag.StringVar(soundFlags[notify.SoundWarning], "warning-sound", "", "Sound file played when one minute is left")
+	flag.IntVar(&volumeFlag, "volume", 100, "Volume of every sound in percent (0-100)")
+	flag.BoolVar(&tickFlag, "tick", false, "Tick every second during work sessions")
+	flag.BoolVar(&tickBreaksFlag, "tick-breaks", false, "Tick during breaks too (with --tick)")
+	flag.IntVar(&countdownFlag, "countdown", 0, "Beep every second of the last N seconds of a session")
+}
+
+// applyTickFlags resolves ticking and the final countdown from the flags
+// and the [notify.sound] table.
+func applyTickFlags(cfg *Config, fs fileSound, setFlags map[string]bool) {
+	cfg.Tick = tickFlag
+	if fs.Ticking != nil && !setFlags["tick"] {
+		cfg.Tick = *fs.Ticking
+	}
+	cfg.TickBreaks = tickBreaksFlag
+	if fs.TickBreaks != nil && !setFlags["tick-breaks"] {
+		cfg.TickBreaks = *fs.TickBreaks
+	}
+	cfg.Countdown = countdownFlag
+	if fs.CountdownSecs != nil && !setFlags["countdown"] {
+		cfg.Countdown = *fs.CountdownSecs
+	}
+	if cfg.Countdown < 0 {
+		fmt.Printf("Error: --countdown must be a positive number\n")
+		os.Exit(1)
+	}
+}
+
+// resolveSounds combines the sound flags with the [notify.sound] tables of
+// the config file. Flags take precedence; missing or unplayable files are
+// reported as errors.
+func resolveSounds(fs fileSound, setFlags map[string]bool) (map[string]notify.SoundSetting, error) {
+	events := map[string]fileSoundEvent{
+		notify.SoundWorkComplete:      fs.WorkComplete,
+		notify.SoundBreakComplete:     fs.BreakComplete,
+		notify.SoundLongBreakComplete: fs.LongBreakComplete,
+		notify.SoundWarning:           fs.Warning,
+		notify.SoundTick:              fs.Tick,
+		notify.SoundCountdown:         fs.Countdown,
+	}
+
+	defaultVolume := 100
+	if fs.Volume != nil {
+		defaultVolume = *fs.Volume
+	}
+	if err := checkVolume("volume", defaultVolume); err != nil {
+		return nil, err
+	}
+	if setFlags["volume"] {
+		if err := checkVolume("--volume", volumeFlag); err != nil {
+			return nil, err
+		}
+	}
+
+	sounds := map[string]notify.SoundSetting{}
+	for _, event := range notify.SoundEvents {
+		fe := events[event]
+		file := fe.File
+		if f := soundFlags[event]; f != nil && *f != "" {
+			file = *f
+		}
+		volume := defaultVolume
+		if fe.Volume != nil {
+			volume = *fe.Volume
+		}
+		if setFlags["volume"] {
+			volume = volumeFlag
+		}
+		if err := checkVolume(event+" volume", volume); err != nil {
+			return nil, err
+		}
+		if file == "" && fe.Volume == nil && fs.Volume == nil && !setFlags["volume"] {
+			continue
+		}
+
+		if file != "" {
+			file = expandHome(file)
+			if err := notify.CheckSoundFile(file); err != nil {
+				return nil, fmt.Errorf("%s sound: %w", event, err)
+			}
+		}
+		sounds[event] = notify.SoundSetting{File: file, Volume: float64(volume) / 100}
+	}
+
+	// A long break without its own file plays the break file.
+	if long, ok := sounds[notify.SoundLongBreakComplete]; ok && long.File == "" {
+		long.File = sounds[notify.SoundBreakComplete].File
+		sounds[notify.SoundLongBreakComplete] = long
+	}
+	return sounds, nil
+}
+
+func checkVolume(name string, volume int) error {
+	if volume < 0 || volume > 100 {
+		return fmt.Errorf("%s must be between 0 and 100, got %d", name, volume)
+	}
+	return nil
+}
+
+// expandHome replaces a leading ~/ with the user's home directory.
+func expandHome(path string) string {
+	if !strings.HasPrefix(path, "~/") {
+		return path
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return path
+	}
+	return filepath.Join(home, path[2:])
+}
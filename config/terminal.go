@@ -0,0 +1,42 @@
+package config
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"termidoro/notify"
+)
+
+// applyTerminal sets up the terminal notifier from --terminal-notify and
+// the [notify.terminal] table. Over SSH it is turned on by default and takes
+// over from desktop notifications, which would not reach the user's screen
+// there. Inside a local tmux it is turned on next to desktop notifications.
+func applyTerminal(cfg *Config, fn fileNotify, setFlags map[string]bool, getenv func(string) string) error {
+	ft := fn.Terminal
+	cfg.Terminal = notify.DetectTerminal(getenv)
+	if ft.Protocol != "" && ft.Protocol != "auto" {
+		if !slices.Contains(notify.TerminalProtocols, ft.Protocol) {
+			return fmt.Errorf("unknown terminal protocol %q (expected auto, %s)", ft.Protocol, strings.Join(notify.TerminalProtocols, ", "))
+		}
+		cfg.Terminal.Protocol = ft.Protocol
+	}
+	if ft.Progress != nil {
+		cfg.Terminal.Progress = *ft.Progress
+	}
+
+	switch {
+	case setFlags["terminal-notify"]:
+		cfg.TerminalEnabled = terminalFlag
+	case ft.Enabled != nil:
+		cfg.TerminalEnabled = *ft.Enabled
+	case notify.RemoteSession(getenv):
+		cfg.TerminalEnabled = true
+		if fn.Desktop.Enabled == nil {
+			cfg.DesktopEnabled = false
+		}
+	case cfg.Terminal.Tmux:
+		cfg.TerminalEnabled = true
+	}
+	return nil
+}
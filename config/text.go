@@ -0,0 +1,45 @@
+package config
+
+import (
+	"strings"
+
+	"termidoro/notify"
+)
+
+type fileText struct {
+	Title string `toml:"title"`
+	Body  string `toml:"body"`
+}
+
+func toTexts(texts map[string]fileText) map[string]notify.Text {
+	out := make(map[string]notify.Text, len(texts))
+	for event, ft := range texts {
+		out[event] = notify.Text{Title: ft.Title, Body: ft.Body}
+	}
+	return out
+}
+
+// resolveTexts merges the notification texts of the config file with those
+// of the chosen user template, which take precedence per title and body.
+func resolveTexts(file *fileConfig, templateName string) (*notify.Texts, error) {
+	texts := toTexts(file.Notify.Text)
+	for key, ft := range file.Templates {
+		if !strings.EqualFold(key, templateName) {
+			continue
+		}
+		for event, override := range ft.Text {
+			text := texts[event]
+			if override.Title != "" {
+				text.Title = override.Title
+			}
+			if override.Body != "" {
+				text.Body = override.Body
+			}
+			texts[event] = text
+		}
+	}
+	if len(texts) == 0 {
+		return nil, nil
+	}
+	return notify.NewTexts(texts)
+}
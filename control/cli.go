@@ -0,0 +1,64 @@
+package control
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"termidoro/timer"
+)
+
+// Run sends command to the running timer and prints its status. duration is
+// only used by extend.
+func Run(command, duration string, asJSON bool) error {
+	resp, err := Send(SocketPath(), Request{Command: command, Duration: duration})
+	if err != nil {
+		return err
+	}
+	if !resp.OK {
+		return errors.New(resp.Error)
+	}
+
+	if asJSON {
+		return json.NewEncoder(os.Stdout).Encode(resp.Status)
+	}
+	if command == CmdStop {
+		fmt.Println("Timer stopped.")
+		return nil
+	}
+	fmt.Println(Describe(*resp.Status))
+	return nil
+}
+
+// Describe renders a one-line, human readable summary of status.
+func Describe(st Status) string {
+	label := strings.ToUpper(strings.ReplaceAll(st.Type, "_", " "))
+	if st.Type == timer.TypeName(timer.WORK) && st.Name != "" {
+		label = st.Name
+	}
+
+	parts := []string{fmt.Sprintf("%s Cycle %d", label, st.Cycle)}
+	switch st.State {
+	case StateWaiting:
+		parts = append(parts, "waiting for the next session")
+	default:
+		parts = append(parts, fmt.Sprintf("%s left", Clock(st.Remaining)))
+		if st.State == StatePaused {
+			parts = append(parts, "paused")
+		}
+	}
+	return strings.Join(parts, " · ")
+}
+
+// Clock formats seconds as mm:ss, or h:mm:ss for an hour or more.
+func Clock(secs int64) string {
+	if secs < 0 {
+		secs = 0
+	}
+	if secs >= 3600 {
+		return fmt.Sprintf("%d:%02d:%02d", secs/3600, secs%3600/60, secs%60)
+	}
+	return fmt.Sprintf("%02d:%02d", secs/60, secs%60)
+}
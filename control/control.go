@@ -0,0 +1,286 @@
+package control
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// Commands understood by the control socket.
+const (
+	CmdStatus = "status"
+	CmdPause  = "pause"
+	CmdResume = "resume"
+	CmdToggle = "toggle"
+	CmdSkip   = "skip"
+	CmdExtend = "extend"
+	CmdStop   = "stop"
+)
+
+// States reported in Status.State.
+const (
+	StateRunning = "running"
+	StatePaused  = "paused"
+	StateWaiting = "waiting" // between sessions, e.g. at the continue prompt
+)
+
+// commandTimeout bounds how long a client waits for the session loop to
+// pick up a command.
+const commandTimeout = 2 * time.Second
+
+var errNoSession = errors.New("no session is running")
+
+// Request is one line sent by a client.
+type Request struct {
+	Command string `json:"command"`
+	// Duration is used by extend, e.g. "5m" or "-5m". Defaults to 5m.
+	Duration string `json:"duration,omitempty"`
+}
+
+// Response is the single line written back for each request.
+type Response struct {
+	OK     bool    `json:"ok"`
+	Error  string  `json:"error,omitempty"`
+	Status *Status `json:"status,omitempty"`
+}
+
+// Status is a snapshot of the running timer. Durations are whole seconds.
+type Status struct {
+	PID       int    `json:"pid"`
+	State     string `json:"state"`
+	Session   int    `json:"session"`
+	Cycle     int    `json:"cycle"`
+	Type      string `json:"type"`
+	Name      string `json:"name,omitempty"`
+	Planned   int64  `json:"planned_secs"`
+	Elapsed   int64  `json:"elapsed_secs"`
+	Remaining int64  `json:"remaining_secs"`
+}
+
+// Command is a request that has to be carried out by the session loop.
+type Command struct {
+	Name     string
+	Duration time.Duration
+	reply    chan error
+}
+
+// Reply reports the outcome of a command back to the waiting client.
+func (c Command) Reply(err error) {
+	c.reply <- err
+}
+
+// SocketPath returns the control socket location under $XDG_RUNTIME_DIR,
+// falling back to a private directory in the temporary directory.
+func SocketPath() string {
+	if dir := os.Getenv("XDG_RUNTIME_DIR"); dir != "" {
+		return filepath.Join(dir, "termidoro.sock")
+	}
+	return filepath.Join(os.TempDir(), fmt.Sprintf("termidoro-%d", os.Getuid()), "termidoro.sock")
+}
+
+// Server accepts control connections for one running timer.
+type Server struct {
+	path     string
+	listener net.Listener
+	commands chan Command
+	inFlight sync.WaitGroup
+
+	mu     sync.Mutex
+	status Status
+}
+
+// Listen creates the control socket at path. A stale socket left behind by a
+// crashed process is replaced; a live one is reported as an error. The
+// directory holding the socket must not be open to other users, so that
+// nobody else can connect before the socket itself is restricted.
+func Listen(path string) (*Server, error) {
+	if conn, err := net.DialTimeout("unix", path, 200*time.Millisecond); err == nil {
+		conn.Close()
+		return nil, fmt.Errorf("another termidoro is already listening on %s", path)
+	}
+	os.Remove(path)
+
+	if err := privateDir(filepath.Dir(path)); err != nil {
+		return nil, err
+	}
+	listener, err := net.Listen("unix", path)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.Chmod(path, 0o600); err != nil {
+		listener.Close()
+		return nil, err
+	}
+
+	s := &Server{
+		path:     path,
+		listener: listener,
+		commands: make(chan Command),
+		status:   Status{PID: os.Getpid(), State: StateWaiting},
+	}
+	go s.serve()
+	return s, nil
+}
+
+// privateDir creates dir with mode 0700. An existing directory is left as
+// it is, and refused when other users can get into it.
+func privateDir(dir string) error {
+	info, err := os.Stat(dir)
+	if errors.Is(err, os.ErrNotExist) {
+		return os.MkdirAll(dir, 0o700)
+	}
+	if err != nil {
+		return err
+	}
+	if info.Mode().Perm()&0o077 != 0 {
+		return fmt.Errorf("%s is open to other users; use a private directory for the control socket", dir)
+	}
+	return nil
+}
+
+// Commands delivers the commands that change the running session. Only the
+// session loop should receive from it.
+func (s *Server) Commands() <-chan Command {
+	return s.commands
+}
+
+// SetStatus replaces the snapshot returned to status requests.
+func (s *Server) SetStatus(status Status) {
+	status.PID = os.Getpid()
+	s.mu.Lock()
+	s.status = status
+	s.mu.Unlock()
+}
+
+// SetState updates only the state of the current snapshot.
+func (s *Server) SetState(state string) {
+	s.mu.Lock()
+	s.status.State = state
+	s.mu.Unlock()
+}
+
+func (s *Server) Status() Status {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.status
+}
+
+// Close stops accepting connections, lets in-flight requests (such as the
+// stop that ended the run) send their reply, and removes the socket.
+func (s *Server) Close() error {
+	err := s.listener.Close()
+	s.inFlight.Wait()
+	os.Remove(s.path)
+	return err
+}
+
+func (s *Server) serve() {
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			return
+		}
+		s.inFlight.Add(1)
+		go func() {
+			defer s.inFlight.Done()
+			s.handle(conn)
+		}()
+	}
+}
+
+func (s *Server) handle(conn net.Conn) {
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(2 * commandTimeout))
+
+	line, err := bufio.NewReader(conn).ReadBytes('\n')
+	if err != nil && len(line) == 0 {
+		return
+	}
+	var req Request
+	if err := json.Unmarshal(line, &req); err != nil {
+		writeResponse(conn, Response{Error: "invalid request: " + err.Error()})
+		return
+	}
+
+	if err := s.dispatch(req); err != nil {
+		writeResponse(conn, Response{Error: err.Error()})
+		return
+	}
+	status := s.Status()
+	writeResponse(conn, Response{OK: true, Status: &status})
+}
+
+func (s *Server) dispatch(req Request) error {
+	cmd := Command{Name: req.Command, reply: make(chan error, 1)}
+	switch req.Command {
+	case CmdStatus:
+		return nil
+	case CmdPause, CmdResume, CmdToggle, CmdSkip, CmdStop:
+	case CmdExtend:
+		cmd.Duration = 5 * time.Minute
+		if req.Duration != "" {
+			d, err := time.ParseDuration(req.Duration)
+			if err != nil {
+				return fmt.Errorf("invalid duration %q", req.Duration)
+			}
+			cmd.Duration = d
+		}
+	default:
+		return fmt.Errorf("unknown command %q", req.Command)
+	}
+
+	// Between sessions only stop is taken, by the prompt that is waiting.
+	if s.Status().State == StateWaiting && req.Command != CmdStop {
+		return errNoSession
+	}
+	select {
+	case s.commands <- cmd:
+	case <-time.After(commandTimeout):
+		return errNoSession
+	}
+	select {
+	case err := <-cmd.reply:
+		return err
+	case <-time.After(commandTimeout):
+		return errors.New("timed out waiting for the timer")
+	}
+}
+
+func writeResponse(conn net.Conn, resp Response) {
+	data, _ := json.Marshal(resp)
+	conn.Write(append(data, '\n'))
+}
+
+// Send delivers req to the timer listening on path.
+func Send(path string, req Request) (*Response, error) {
+	conn, err := net.DialTimeout("unix", path, time.Second)
+	if err != nil {
+		return nil, fmt.Errorf("no running termidoro found at %s", path)
+	}
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(3 * commandTimeout))
+
+	data, err := json.Marshal(req)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := conn.Write(append(data, '\n')); err != nil {
+		return nil, err
+	}
+
+	line, err := bufio.NewReader(conn).ReadBytes('\n')
+	if err != nil && len(line) == 0 {
+		return nil, err
+	}
+	var resp Response
+	if err := json.Unmarshal(line, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
@@ -0,0 +1,149 @@
+package control
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"time"
+)
+
+func TestServerRoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "run", "t.sock")
+	server, err := Listen(path)
+	if err != nil {
+		t.Fatalf("Listen: %v", err)
+	}
+	defer server.Close()
+
+	if _, err := Listen(path); err == nil {
+		t.Error("Expected a second server on the same socket to fail")
+	}
+
+	// Commands are refused while no session is running.
+	resp, err := Send(path, Request{Command: CmdPause})
+	if err != nil || resp.OK || resp.Error != errNoSession.Error() {
+		t.Errorf("Expected no-session error, got %+v, %v", resp, err)
+	}
+	// Status and stop still work between sessions.
+	if resp, err := Send(path, Request{Command: CmdStatus}); err != nil || !resp.OK || resp.Status.State != StateWaiting {
+		t.Errorf("Expected the waiting status, got %+v, %v", resp, err)
+	}
+	go func() {
+		cmd := <-server.Commands()
+		cmd.Reply(nil)
+	}()
+	if resp, err := Send(path, Request{Command: CmdStop}); err != nil || !resp.OK {
+		t.Errorf("Expected stop to reach the waiting prompt, got %+v, %v", resp, err)
+	}
+
+	server.SetStatus(Status{State: StateRunning, Session: 1, Cycle: 1, Type: "work", Planned: 1500, Remaining: 1500})
+	go func() {
+		cmd := <-server.Commands()
+		if cmd.Name != CmdExtend || cmd.Duration != -2*time.Minute {
+			t.Errorf("Unexpected command %+v", cmd)
+		}
+		server.SetStatus(Status{State: StateRunning, Session: 1, Cycle: 1, Type: "work", Planned: 1380, Remaining: 1380})
+		cmd.Reply(nil)
+	}()
+
+	resp, err = Send(path, Request{Command: CmdExtend, Duration: "-2m"})
+	if err != nil || !resp.OK {
+		t.Fatalf("Expected extend to succeed, got %+v, %v", resp, err)
+	}
+	if resp.Status.Remaining != 1380 {
+		t.Errorf("Expected updated status in reply, got %+v", resp.Status)
+	}
+
+	resp, err = Send(path, Request{Command: CmdStatus})
+	if err != nil || !resp.OK || resp.Status.Type != "work" {
+		t.Errorf("Unexpected status reply %+v, %v", resp, err)
+	}
+
+	resp, err = Send(path, Request{Command: "dance"})
+	if err != nil || resp.OK {
+		t.Errorf("Expected unknown command to fail, got %+v, %v", resp, err)
+	}
+}
+
+func TestListenPermissions(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("Unix permissions")
+	}
+	dir := filepath.Join(t.TempDir(), "private")
+	path := filepath.Join(dir, "t.sock")
+	server, err := Listen(path)
+	if err != nil {
+		t.Fatalf("Listen: %v", err)
+	}
+	defer server.Close()
+
+	for name, want := range map[string]os.FileMode{dir: 0o700, path: 0o600} {
+		info, err := os.Stat(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := info.Mode().Perm(); got != want {
+			t.Errorf("Expected %s to have mode %o, got %o", name, want, got)
+		}
+	}
+
+	// A shared directory is refused and left alone.
+	shared := filepath.Join(t.TempDir(), "shared")
+	if err := os.Mkdir(shared, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chmod(shared, 0o777); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Listen(filepath.Join(shared, "t.sock")); err == nil {
+		t.Error("Expected a shared directory to be refused")
+	}
+	if info, err := os.Stat(shared); err != nil || info.Mode().Perm() != 0o777 {
+		t.Errorf("Expected the shared directory to keep its mode, got %v, %v", info.Mode(), err)
+	}
+}
+
+func TestDescribe(t *testing.T) {
+	st := Status{State: StatePaused, Cycle: 2, Type: "work", Name: "Deep Work", Remaining: 754}
+	if got, want := Describe(st), "Deep Work Cycle 2 · 12:34 left · paused"; got != want {
+		t.Errorf("Expected %q, got %q", want, got)
+	}
+	st = Status{State: StateRunning, Cycle: 4, Type: "long_break", Remaining: 3725}
+	if got, want := Describe(st), "LONG BREAK Cycle 4 · 1:02:05 left"; got != want {
+		t.Errorf("Expected %q, got %q", want, got)
+	}
+}
+
+func TestFormatLine(t *testing.T) {
+	st := &Status{State: StateRunning, Cycle: 3, Type: "work", Name: "Deep Work", Planned: 1500, Elapsed: 746, Remaining: 754}
+
+	tests := []struct {
+		format string
+		want   string
+	}{
+		{"default", "🍅 12:34 Deep Work #3"},
+		{"tmux", "#[fg=#8b5cf6]🍅 12:34#[default] Deep Work #3"},
+		{"{{.Clock}} {{.Percent}}%", "12:34 49%"},
+		{"waybar", `{"text":"🍅 12:34 Deep Work #3","tooltip":"Deep Work Cycle 3 · 12:34 left","class":"work","alt":"running","percentage":49}`},
+	}
+	for _, tc := range tests {
+		got, err := FormatLine(tc.format, st)
+		if err != nil {
+			t.Errorf("%s: %v", tc.format, err)
+		}
+		if got != tc.want {
+			t.Errorf("%s: expected %q, got %q", tc.format, tc.want, got)
+		}
+	}
+
+	if got, _ := FormatLine("default", nil); got != "" {
+		t.Errorf("Expected empty idle line, got %q", got)
+	}
+	if got, _ := FormatLine("waybar", nil); got != `{"text":"","class":"idle","percentage":0}` {
+		t.Errorf("Unexpected waybar idle line %q", got)
+	}
+	if _, err := FormatLine("{{.Nope", st); err == nil {
+		t.Error("Expected error for invalid template")
+	}
+}
@@ -0,0 +1,125 @@
+package control
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"text/template"
+)
+
+// Presets for --format. Any other value is parsed as a text/template over
+// LineData.
+var presets = map[string]string{
+	"default":  `{{.Icon}} {{if .Waiting}}waiting{{else}}{{.Clock}}{{end}} {{.Label}} #{{.Cycle}}{{if .Paused}} ⏸{{end}}`,
+	"tmux":     `#[fg={{.Color}}]{{.Icon}} {{if .Waiting}}waiting{{else}}{{.Clock}}{{end}}#[default] {{.Label}} #{{.Cycle}}{{if .Paused}} ⏸{{end}}`,
+	"polybar":  `%{F{{.Color}}}{{.Icon}} {{if .Waiting}}waiting{{else}}{{.Clock}}{{end}}%{F-} {{.Label}} #{{.Cycle}}{{if .Paused}} ⏸{{end}}`,
+	"i3blocks": "{{.Icon}} {{if .Waiting}}waiting{{else}}{{.Clock}}{{end}} {{.Label}} #{{.Cycle}}{{if .Paused}} ⏸{{end}}\n{{.Icon}} {{.Clock}}\n{{.Color}}",
+	"waybar":   "", // JSON, built by waybarLine
+}
+
+// LineData is what status line templates can refer to.
+type LineData struct {
+	Status
+	Icon    string
+	Label   string
+	Clock   string
+	Color   string
+	Percent int
+	Paused  bool
+	Waiting bool
+}
+
+func newLineData(st Status) LineData {
+	d := LineData{
+		Status:  st,
+		Clock:   Clock(st.Remaining),
+		Paused:  st.State == StatePaused,
+		Waiting: st.State == StateWaiting,
+	}
+	switch st.Type {
+	case "break":
+		d.Icon, d.Label, d.Color = "☕", "Break", "#fb923c"
+	case "long_break":
+		d.Icon, d.Label, d.Color = "🌴", "Long Break", "#2dd4bf"
+	default:
+		d.Icon, d.Label, d.Color = "🍅", "Work", "#8b5cf6"
+		if st.Name != "" {
+			d.Label = st.Name
+		}
+	}
+	if st.Planned > 0 {
+		d.Percent = int(st.Elapsed * 100 / st.Planned)
+	}
+	return d
+}
+
+// FormatLine renders status with a preset name or a template. A nil status
+// means no timer is running and yields the preset's idle output.
+func FormatLine(format string, st *Status) (string, error) {
+	if format == "waybar" {
+		return waybarLine(st)
+	}
+	if st == nil {
+		return "", nil
+	}
+
+	text, ok := presets[format]
+	if !ok {
+		text = format
+	}
+	tmpl, err := template.New("status").Parse(text)
+	if err != nil {
+		return "", fmt.Errorf("invalid status format: %w", err)
+	}
+	var sb strings.Builder
+	if err := tmpl.Execute(&sb, newLineData(*st)); err != nil {
+		return "", fmt.Errorf("invalid status format: %w", err)
+	}
+	return sb.String(), nil
+}
+
+type waybarOutput struct {
+	Text       string `json:"text"`
+	Tooltip    string `json:"tooltip,omitempty"`
+	Class      string `json:"class"`
+	Alt        string `json:"alt,omitempty"`
+	Percentage int    `json:"percentage"`
+}
+
+func waybarLine(st *Status) (string, error) {
+	out := waybarOutput{Class: "idle"}
+	if st != nil {
+		text, err := FormatLine("default", st)
+		if err != nil {
+			return "", err
+		}
+		d := newLineData(*st)
+		out = waybarOutput{
+			Text:       text,
+			Tooltip:    Describe(*st),
+			Class:      st.Type,
+			Alt:        st.State,
+			Percentage: d.Percent,
+		}
+		if d.Paused || d.Waiting {
+			out.Class = st.State
+		}
+	}
+	data, err := json.Marshal(out)
+	return string(data), err
+}
+
+// StatusLine prints one line for status bars. When no timer is running it
+// prints the idle output and succeeds, so bars that poll stay quiet.
+func StatusLine(format string) error {
+	var st *Status
+	if resp, err := Send(SocketPath(), Request{Command: CmdStatus}); err == nil && resp.OK {
+		st = resp.Status
+	}
+	line, err := FormatLine(format, st)
+	if err != nil {
+		return err
+	}
+	fmt.Println(line)
+	return nil
+}
@@ -0,0 +1,186 @@
+package history
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"termidoro/timer"
+)
+
+const fileName = "history.jsonl"
+
+// Record is a single finished session as stored on disk. Durations are
+// serialised in nanoseconds, matching time.Duration.
+type Record struct {
+	Type      string        `json:"type"`
+	Name      string        `json:"name,omitempty"`
+	Cycle     int           `json:"cycle"`
+	Planned   time.Duration `json:"planned"`
+	Elapsed   time.Duration `json:"elapsed"`
+	StartTime time.Time     `json:"start"`
+	EndTime   time.Time     `json:"end"`
+	Paused    time.Duration `json:"paused,omitempty"`
+	Extended  time.Duration `json:"extended,omitempty"`
+	Restarts  int           `json:"restarts,omitempty"`
+	Completed bool          `json:"completed"`
+	Cancelled bool          `json:"cancelled"`
+	Skipped   bool          `json:"skipped,omitempty"`
+	Task      int           `json:"task,omitempty"`
+	Voided    bool          `json:"voided,omitempty"`
+	Note      string        `json:"note,omitempty"`
+	Intention string        `json:"intention,omitempty"`
+	Outcome   string        `json:"outcome,omitempty"`
+
+	Interruptions []Interruption `json:"interruptions,omitempty"`
+}
+
+// Interruption is an interruption logged during a session.
+type Interruption struct {
+	Time     time.Time `json:"time"`
+	External bool      `json:"external,omitempty"`
+	Note     string    `json:"note,omitempty"`
+}
+
+func NewRecord(s timer.Session) Record {
+	r := Record{
+		Type:      timer.TypeName(s.Type),
+		Name:      s.Name,
+		Cycle:     s.Cycle,
+		Planned:   s.Duration,
+		Elapsed:   s.Elapsed,
+		StartTime: s.StartTime,
+		EndTime:   s.EndTime,
+		Paused:    s.Paused,
+		Extended:  s.Extended,
+		Restarts:  s.Restarts,
+		Completed: s.Completed,
+		Cancelled: s.WasCancelled,
+		Skipped:   s.Skipped,
+		Task:      s.Task,
+		Voided:    s.Voided,
+		Note:      s.Note,
+		Intention: s.Intention,
+		Outcome:   s.Outcome,
+	}
+	for _, i := range s.Interruptions {
+		r.Interruptions = append(r.Interruptions, Interruption{Time: i.Time, External: i.External, Note: i.Note})
+	}
+	return r
+}
+
+// Store appends records to a JSON-lines file. Every access takes an advisory
+// file lock so several termidoro processes can share the same history.
+type Store struct {
+	path string
+}
+
+func NewStore(path string) *Store {
+	return &Store{path: path}
+}
+
+// DataDir returns the termidoro directory under $XDG_DATA_HOME, falling back
+// to ~/.local/share when the variable is unset.
+func DataDir() (string, error) {
+	base := os.Getenv("XDG_DATA_HOME")
+	if base == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", err
+		}
+		base = filepath.Join(home, ".local", "share")
+	}
+	return filepath.Join(base, "termidoro"), nil
+}
+
+// DefaultPath returns the location of the shared history file.
+func DefaultPath() (string, error) {
+	dir, err := DataDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, fileName), nil
+}
+
+// SnoozePath returns the snooze state file. It is shared by every
+// termidoro process of the user.
+func SnoozePath() (string, error) {
+	dir, err := DataDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "snooze"), nil
+}
+
+func (s *Store) Path() string {
+	return s.path
+}
+
+// Record implements timer.Recorder.
+func (s *Store) Record(session timer.Session) error {
+	return s.Append(NewRecord(session))
+}
+
+func (s *Store) Append(r Record) error {
+	if err := os.MkdirAll(filepath.Dir(s.path), 0o755); err != nil {
+		return err
+	}
+
+	f, err := os.OpenFile(s.path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	if err := lockFile(f, true); err != nil {
+		return fmt.Errorf("lock %s: %w", s.path, err)
+	}
+	defer unlockFile(f)
+
+	line, err := json.Marshal(r)
+	if err != nil {
+		return err
+	}
+	_, err = f.Write(append(line, '\n'))
+	return err
+}
+
+// Load returns every record in the file. A missing file is not an error.
+// Lines that cannot be decoded (for example a partially written entry) are
+// skipped.
+func (s *Store) Load() ([]Record, error) {
+	f, err := os.Open(s.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	if err := lockFile(f, false); err != nil {
+		return nil, fmt.Errorf("lock %s: %w", s.path, err)
+	}
+	defer unlockFile(f)
+
+	var records []Record
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		var r Record
+		if err := json.Unmarshal([]byte(line), &r); err != nil {
+			continue
+		}
+		records = append(records, r)
+	}
+	return records, scanner.Err()
+}
@@ -0,0 +1,109 @@
+package history
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"termidoro/timer"
+)
+
+func TestStoreRecordAndLoad(t *testing.T) {
+	store := NewStore(filepath.Join(t.TempDir(), "nested", fileName))
+
+	records, err := store.Load()
+	if err != nil {
+		t.Fatalf("Load on missing file: %v", err)
+	}
+	if len(records) != 0 {
+		t.Fatalf("Expected no records, got %d", len(records))
+	}
+
+	start := time.Date(2025, 3, 10, 9, 0, 0, 0, time.UTC)
+	sessions := []timer.Session{
+		{Type: timer.WORK, Name: "Deep Work", Cycle: 1, Duration: 25 * time.Minute, StartTime: start, EndTime: start.Add(25 * time.Minute), Completed: true},
+		{Type: timer.BREAK, Name: "Deep Work", Cycle: 1, Duration: 5 * time.Minute, StartTime: start.Add(25 * time.Minute), EndTime: start.Add(27 * time.Minute), WasCancelled: true},
+		{Type: timer.WORK, Name: "Deep Work", Cycle: 2, Duration: 25 * time.Minute, StartTime: start.Add(30 * time.Minute), EndTime: start.Add(40 * time.Minute), Voided: true,
+			Interruptions: []timer.Interruption{{Time: start.Add(32 * time.Minute), Note: "mail"}, {Time: start.Add(35 * time.Minute), External: true}}},
+	}
+	for _, s := range sessions {
+		if err := store.Record(s); err != nil {
+			t.Fatalf("Record: %v", err)
+		}
+	}
+
+	records, err = store.Load()
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if len(records) != 3 {
+		t.Fatalf("Expected 3 records, got %d", len(records))
+	}
+	if records[0].Type != "work" || !records[0].Completed || records[0].Planned != 25*time.Minute {
+		t.Errorf("Unexpected first record: %+v", records[0])
+	}
+	if records[1].Type != "break" || !records[1].Cancelled || !records[1].EndTime.Equal(start.Add(27*time.Minute)) {
+		t.Errorf("Unexpected second record: %+v", records[1])
+	}
+	if !records[2].Voided || len(records[2].Interruptions) != 2 || records[2].Interruptions[0].Note != "mail" || !records[2].Interruptions[1].External {
+		t.Errorf("Unexpected third record: %+v", records[2])
+	}
+
+}
+
+func TestTaskStore(t *testing.T) {
+	store := NewTaskStore(filepath.Join(t.TempDir(), "nested", taskFileName))
+	tasks, err := store.Load()
+	if err != nil || len(tasks) != 0 {
+		t.Fatalf("Expected no tasks for a missing file, got %v, %v", tasks, err)
+	}
+
+	for _, name := range []string{"Write report", "Review PR"} {
+		err := store.Update(func(tasks []Task) ([]Task, error) {
+			return append(tasks, Task{ID: NextTaskID(tasks), Name: name, Estimate: 3}), nil
+		})
+		if err != nil {
+			t.Fatalf("Update: %v", err)
+		}
+	}
+	err = store.Update(func(tasks []Task) ([]Task, error) {
+		i, err := FindTask(tasks, "review pr")
+		if err != nil {
+			return nil, err
+		}
+		tasks[i].Done = true
+		return tasks[:i+1], nil
+	})
+	if err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+
+	tasks, err = store.Load()
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if len(tasks) != 2 || tasks[1].ID != 2 || !tasks[1].Done || tasks[0].Estimate != 3 {
+		t.Errorf("Unexpected tasks: %+v", tasks)
+	}
+	if i, err := FindTask(tasks, "1"); err != nil || i != 0 {
+		t.Errorf("Expected to find task 1, got %d, %v", i, err)
+	}
+	if _, err := FindTask(tasks, "7"); err == nil {
+		t.Error("Expected error for an unknown ID")
+	}
+}
+
+func TestCountPomodoros(t *testing.T) {
+	records := []Record{
+		{Type: "work", Task: 1, Completed: true},
+		{Type: "work", Task: 1, Completed: true},
+		{Type: "break", Task: 1, Completed: true},
+		{Type: "work", Task: 1, Cancelled: true},
+		{Type: "work", Task: 2, Completed: true},
+		{Type: "work", Completed: true},
+	}
+	counts := CountPomodoros(records)
+	if counts[1] != 2 || counts[2] != 1 || len(counts) != 2 {
+		t.Errorf("Unexpected counts: %v", counts)
+	}
+}
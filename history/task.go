@@ -0,0 +1,150 @@
+package history
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"termidoro/timer"
+)
+
+const taskFileName = "tasks.json"
+
+// Task is a piece of work that completed work sessions count against.
+type Task struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+	// Estimate is the number of pomodoros the task is expected to take;
+	// zero when not estimated.
+	Estimate int       `json:"estimate,omitempty"`
+	Done     bool      `json:"done,omitempty"`
+	Created  time.Time `json:"created"`
+}
+
+// TaskStore keeps the task list in a JSON file, locked like the history.
+type TaskStore struct {
+	path string
+}
+
+func NewTaskStore(path string) *TaskStore {
+	return &TaskStore{path: path}
+}
+
+// DefaultTaskPath returns the location of the shared task list.
+func DefaultTaskPath() (string, error) {
+	dir, err := DataDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, taskFileName), nil
+}
+
+// Load returns every task. A missing file is not an error.
+func (s *TaskStore) Load() ([]Task, error) {
+	f, err := os.Open(s.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	if err := lockFile(f, false); err != nil {
+		return nil, fmt.Errorf("lock %s: %w", s.path, err)
+	}
+	defer unlockFile(f)
+	return readTasks(f)
+}
+
+// Update rewrites the task list with the result of change, holding the lock
+// throughout so concurrent updates are not lost.
+func (s *TaskStore) Update(change func([]Task) ([]Task, error)) error {
+	if err := os.MkdirAll(filepath.Dir(s.path), 0o755); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(s.path, os.O_CREATE|os.O_RDWR, 0o644)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	if err := lockFile(f, true); err != nil {
+		return fmt.Errorf("lock %s: %w", s.path, err)
+	}
+	defer unlockFile(f)
+
+	tasks, err := readTasks(f)
+	if err != nil {
+		return err
+	}
+	if tasks, err = change(tasks); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(tasks, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := f.Truncate(0); err != nil {
+		return err
+	}
+	_, err = f.WriteAt(append(data, '\n'), 0)
+	return err
+}
+
+func readTasks(f *os.File) ([]Task, error) {
+	data, err := io.ReadAll(f)
+	if err != nil || len(strings.TrimSpace(string(data))) == 0 {
+		return nil, err
+	}
+	var tasks []Task
+	if err := json.Unmarshal(data, &tasks); err != nil {
+		return nil, fmt.Errorf("%s: %w", f.Name(), err)
+	}
+	return tasks, nil
+}
+
+// FindTask returns the index of the task with the given ID or, ignoring
+// case, name.
+func FindTask(tasks []Task, ref string) (int, error) {
+	if id, err := strconv.Atoi(ref); err == nil {
+		for i, t := range tasks {
+			if t.ID == id {
+				return i, nil
+			}
+		}
+		return -1, fmt.Errorf("no task with ID %d", id)
+	}
+	for i, t := range tasks {
+		if strings.EqualFold(t.Name, ref) {
+			return i, nil
+		}
+	}
+	return -1, fmt.Errorf("no task named %q", ref)
+}
+
+// NextTaskID returns an ID not used by any of tasks.
+func NextTaskID(tasks []Task) int {
+	id := 0
+	for _, t := range tasks {
+		id = max(id, t.ID)
+	}
+	return id + 1
+}
+
+// CountPomodoros counts the completed work sessions of each task.
+func CountPomodoros(records []Record) map[int]int {
+	counts := map[int]int{}
+	for _, r := range records {
+		if r.Task != 0 && r.Type == timer.TypeName(timer.WORK) && r.Completed {
+			counts[r.Task]++
+		}
+	}
+	return counts
+}
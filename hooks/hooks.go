@@ -0,0 +1,130 @@
+package hooks
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Event names that hooks can be attached to.
+const (
+	WorkStart  = "work_start"
+	WorkEnd    = "work_end"
+	BreakStart = "break_start"
+	BreakEnd   = "break_end"
+	CycleEnd   = "cycle_end"
+	Cancel     = "cancel"
+	Pause      = "pause"
+	Resume     = "resume"
+)
+
+// Events lists every supported event in lifecycle order.
+var Events = []string{WorkStart, WorkEnd, BreakStart, BreakEnd, CycleEnd, Cancel, Pause, Resume}
+
+// timeout bounds how long a single hook command may run.
+const timeout = time.Minute
+
+// IsEvent reports whether name is a supported event.
+func IsEvent(name string) bool {
+	for _, e := range Events {
+		if e == name {
+			return true
+		}
+	}
+	return false
+}
+
+// Info describes the session an event belongs to. It is passed to hook
+// commands as TERMIDORO_* environment variables.
+type Info struct {
+	Type     string
+	Name     string
+	Duration time.Duration
+	Cycle    int
+	Session  int
+	// Status is "completed", "skipped" or "voided" for *_end events.
+	Status string
+}
+
+func (i Info) environ(event string) []string {
+	return append(os.Environ(),
+		"TERMIDORO_EVENT="+event,
+		"TERMIDORO_SESSION_TYPE="+i.Type,
+		"TERMIDORO_SESSION_NAME="+i.Name,
+		"TERMIDORO_DURATION="+strconv.FormatInt(int64(i.Duration/time.Second), 10),
+		"TERMIDORO_CYCLE="+strconv.Itoa(i.Cycle),
+		"TERMIDORO_SESSION="+strconv.Itoa(i.Session),
+		"TERMIDORO_STATUS="+i.Status,
+	)
+}
+
+// Runner runs the shell commands configured for each event.
+type Runner struct {
+	commands map[string][]string
+	running  sync.WaitGroup
+}
+
+// New returns a Runner for commands keyed by event name.
+func New(commands map[string][]string) (*Runner, error) {
+	for event := range commands {
+		if !IsEvent(event) {
+			return nil, fmt.Errorf("unknown hook event %q", event)
+		}
+	}
+	return &Runner{commands: commands}, nil
+}
+
+// Run starts the commands for event in the background so the countdown is
+// never held up. A nil Runner does nothing.
+func (r *Runner) Run(event string, info Info) {
+	if r == nil {
+		return
+	}
+	for _, command := range r.commands[event] {
+		r.running.Add(1)
+		go func() {
+			defer r.running.Done()
+			run(command, info.environ(event))
+		}()
+	}
+}
+
+// Wait gives hooks that are still running up to limit to finish, so that
+// hooks fired as the run ends (such as cancel) are not cut off.
+func (r *Runner) Wait(limit time.Duration) {
+	if r == nil {
+		return
+	}
+	done := make(chan struct{})
+	go func() {
+		r.running.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(limit):
+	}
+}
+
+func run(command string, env []string) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	cmd := shellCommand(ctx, command)
+	cmd.Env = env
+	if err := cmd.Run(); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: Hook %q failed: %v\n", command, err)
+	}
+}
+
+func shellCommand(ctx context.Context, command string) *exec.Cmd {
+	if runtime.GOOS == "windows" {
+		return exec.CommandContext(ctx, "cmd", "/C", command)
+	}
+	return exec.CommandContext(ctx, "sh", "-c", command)
+}
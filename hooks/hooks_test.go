@@ -0,0 +1,49 @@
+package hooks
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestNewRejectsUnknownEvent(t *testing.T) {
+	if _, err := New(map[string][]string{"lunch": {"true"}}); err == nil {
+		t.Error("Expected error for unknown event")
+	}
+}
+
+func TestRunPassesEnvironment(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses sh")
+	}
+	out := filepath.Join(t.TempDir(), "out")
+	r, err := New(map[string][]string{
+		WorkEnd: {`echo "$TERMIDORO_EVENT $TERMIDORO_SESSION_TYPE $TERMIDORO_SESSION_NAME $TERMIDORO_DURATION $TERMIDORO_CYCLE $TERMIDORO_SESSION $TERMIDORO_STATUS" > ` + out},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	r.Run(WorkStart, Info{}) // no commands configured
+	r.Run(WorkEnd, Info{Type: "work", Name: "Deep", Duration: 25 * time.Minute, Cycle: 2, Session: 3, Status: "completed"})
+
+	want := "work_end work Deep 1500 2 3 completed"
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		data, _ := os.ReadFile(out)
+		if strings.TrimSpace(string(data)) == want {
+			return
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+	data, _ := os.ReadFile(out)
+	t.Errorf("Expected %q, got %q", want, strings.TrimSpace(string(data)))
+}
+
+func TestNilRunner(t *testing.T) {
+	var r *Runner
+	r.Run(WorkStart, Info{})
+}
@@ -0,0 +1,74 @@
+package journal
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"termidoro/history"
+	"termidoro/timer"
+)
+
+// defaultDays is how far back "termidoro log" goes without --days.
+const defaultDays = 7
+
+// Run prints the journal of the last days from the shared history.
+func Run(args []string) error {
+	fs := flag.NewFlagSet("termidoro log", flag.ExitOnError)
+	days := fs.Int("days", defaultDays, "Number of days to show, including today")
+	fs.Parse(args)
+	if *days < 1 || fs.NArg() > 0 {
+		return fmt.Errorf("usage: termidoro log [--days n]")
+	}
+
+	path, err := history.DefaultPath()
+	if err != nil {
+		return err
+	}
+	records, err := history.NewStore(path).Load()
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	if !Print(os.Stdout, records, today.AddDate(0, 0, 1-*days)) {
+		fmt.Printf("No journal entries in the last %d days.\n", *days)
+	}
+	return nil
+}
+
+// Print writes the notes of the work sessions that started since from,
+// grouped by day, oldest first. It reports whether there were any.
+func Print(w io.Writer, records []history.Record, from time.Time) bool {
+	var day time.Time
+	for _, r := range records {
+		if r.Type != timer.TypeName(timer.WORK) || r.Note == "" || r.StartTime.Before(from) {
+			continue
+		}
+		start := r.StartTime.In(from.Location())
+		if y, m, d := start.Date(); day.IsZero() || !sameDay(day, y, m, d) {
+			if !day.IsZero() {
+				fmt.Fprintln(w)
+			}
+			day = time.Date(y, m, d, 0, 0, 0, 0, from.Location())
+			fmt.Fprintln(w, day.Format("Monday, January 2"))
+		}
+		fmt.Fprintf(w, "- %s-%s ", start.Format("15:04"), r.EndTime.In(from.Location()).Format("15:04"))
+		if r.Name != "" {
+			fmt.Fprintf(w, "%s: ", r.Name)
+		}
+		fmt.Fprint(w, r.Note)
+		if !r.Completed {
+			fmt.Fprint(w, " (unfinished)")
+		}
+		fmt.Fprintln(w)
+	}
+	return !day.IsZero()
+}
+
+func sameDay(day time.Time, y int, m time.Month, d int) bool {
+	dy, dm, dd := day.Date()
+	return dy == y && dm == m && dd == d
+}
@@ -0,0 +1,39 @@
+package journal
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"termidoro/history"
+)
+
+func TestPrint(t *testing.T) {
+	day := time.Date(2025, 3, 12, 0, 0, 0, 0, time.UTC)
+	at := func(hour int) time.Time { return day.Add(time.Duration(hour) * time.Hour) }
+	records := []history.Record{
+		{Type: "work", Name: "Deep Work", StartTime: at(-15), EndTime: at(-15).Add(25 * time.Minute), Completed: true, Note: "Wrote the parser tests"},
+		{Type: "break", StartTime: at(-14), EndTime: at(-14).Add(5 * time.Minute), Completed: true, Note: "Coffee"},
+		{Type: "work", StartTime: at(9), EndTime: at(9).Add(10 * time.Minute), Cancelled: true, Note: "Reviewed PR 12"},
+		{Type: "work", StartTime: at(10), EndTime: at(10).Add(25 * time.Minute), Completed: true},
+		{Type: "work", StartTime: at(-48), EndTime: at(-48).Add(25 * time.Minute), Completed: true, Note: "Too old"},
+	}
+
+	var out strings.Builder
+	if !Print(&out, records, day.AddDate(0, 0, -1)) {
+		t.Fatal("Expected journal entries")
+	}
+	want := "Tuesday, March 11\n" +
+		"- 09:00-09:25 Deep Work: Wrote the parser tests\n" +
+		"\n" +
+		"Wednesday, March 12\n" +
+		"- 09:00-09:10 Reviewed PR 12 (unfinished)\n"
+	if out.String() != want {
+		t.Errorf("Unexpected journal:\n%s\nwant:\n%s", out.String(), want)
+	}
+
+	out.Reset()
+	if Print(&out, records, day.AddDate(0, 0, 1)) || out.Len() != 0 {
+		t.Errorf("Expected no entries, got:\n%s", out.String())
+	}
+}
@@ -2,21 +2,72 @@ package main
 
 import (
 	"fmt"
+	"os"
 	"termidoro/config"
+	"termidoro/control"
+	"termidoro/history"
+	"termidoro/journal"
 	"termidoro/notify"
 	"termidoro/run"
+	"termidoro/stats"
+	"termidoro/task"
+	"termidoro/ui"
 )
 
 func main() {
-	defer func() {
-		fmt.Print("\033[?25h")
-	}()
-
 	cfg, exit := config.Parse()
 	if exit {
 		return
 	}
 
-	notify.SetSoundEnabled(cfg.SoundEnabled)
-	run.Timer(cfg.WorkDuration, cfg.BreakDuration, cfg.CustomName, cfg.AutoYes)
+	switch {
+	case cfg.Command == config.CommandStats:
+		if err := stats.Run(); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	case cfg.Command == config.CommandStatus && cfg.StatusFormat != "":
+		if err := control.StatusLine(cfg.StatusFormat); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	case cfg.Command == config.CommandTask:
+		if err := task.Run(cfg.CommandArgs); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	case cfg.Command == config.CommandLog:
+		if err := journal.Run(cfg.CommandArgs); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	case cfg.Command == config.CommandSnooze:
+		path, err := history.SnoozePath()
+		if err == nil {
+			err = notify.RunSnooze(path, cfg.CommandArg)
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	case config.IsControlCommand(cfg.Command):
+		if err := control.Run(cfg.Command, cfg.CommandArg, cfg.CommandJSON); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	defer ui.ShowCursor()
+
+	if err := run.Timer(cfg); err != nil {
+		ui.ShowCursor()
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
 }
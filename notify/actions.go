@@ -0,0 +1,20 @@
+package notify
+
+import "errors"
+
+// Keys of the buttons offered on a session-end notification.
+const (
+	ActionContinue = "continue"
+	ActionSnooze   = "snooze"
+	ActionStop     = "stop"
+)
+
+// Action is a button on a desktop notification.
+type Action struct {
+	Key   string
+	Label string
+}
+
+// errNoActions means the desktop cannot show buttons; the notification is
+// shown without them.
+var errNoActions = errors.New("notification actions not supported")
@@ -0,0 +1,83 @@
+//go:build linux
+
+package notify
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/godbus/dbus/v5"
+)
+
+const (
+	notificationsName = "org.freedesktop.Notifications"
+	notificationsPath = dbus.ObjectPath("/org/freedesktop/Notifications")
+)
+
+// notifyWithActions shows a notification with buttons through the
+// freedesktop notification service and sends the key of the button
+// clicked to reply. It returns when the notification is clicked or
+// dismissed, or closes it when done is closed first.
+func notifyWithActions(title, message string, actions []Action, reply chan<- string, done <-chan struct{}) error {
+	conn, err := dbus.ConnectSessionBus()
+	if err != nil {
+		return fmt.Errorf("%w: %v", errNoActions, err)
+	}
+	defer conn.Close()
+	obj := conn.Object(notificationsName, notificationsPath)
+
+	var caps []string
+	if err := obj.Call(notificationsName+".GetCapabilities", 0).Store(&caps); err != nil {
+		return fmt.Errorf("%w: %v", errNoActions, err)
+	}
+	if !slices.Contains(caps, "actions") {
+		return errNoActions
+	}
+
+	err = conn.AddMatchSignal(dbus.WithMatchObjectPath(notificationsPath), dbus.WithMatchInterface(notificationsName))
+	if err != nil {
+		return err
+	}
+	signals := make(chan *dbus.Signal, 8)
+	conn.Signal(signals)
+
+	buttons := make([]string, 0, 2*len(actions))
+	for _, a := range actions {
+		buttons = append(buttons, a.Key, a.Label)
+	}
+	var id uint32
+	// An expiry of 0 keeps the notification up until it is answered.
+	call := obj.Call(notificationsName+".Notify", 0, "termidoro", uint32(0), "", title, message,
+		buttons, map[string]dbus.Variant{}, int32(0))
+	if err := call.Store(&id); err != nil {
+		return err
+	}
+
+	for {
+		select {
+		case sig, ok := <-signals:
+			if !ok {
+				return nil
+			}
+			if len(sig.Body) < 2 || sig.Body[0] != id {
+				continue
+			}
+			switch sig.Name {
+			case notificationsName + ".ActionInvoked":
+				if key, ok := sig.Body[1].(string); ok {
+					select {
+					case reply <- key:
+					default:
+					}
+				}
+				// Not every server closes the notification on a click.
+				obj.Call(notificationsName+".CloseNotification", 0, id)
+				return nil
+			case notificationsName + ".NotificationClosed":
+				return nil
+			}
+		case <-done:
+			return obj.Call(notificationsName+".CloseNotification", 0, id).Err
+		}
+	}
+}
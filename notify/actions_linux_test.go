@@ -0,0 +1,157 @@
+package notify
+
+import (
+	"bufio"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/godbus/dbus/v5"
+)
+
+// startBus runs a private dbus-daemon for the test and points the session
+// bus at it.
+func startBus(t *testing.T) {
+	t.Helper()
+	if _, err := exec.LookPath("dbus-daemon"); err != nil {
+		t.Skip("dbus-daemon not available")
+	}
+	dir := t.TempDir()
+	conf := filepath.Join(dir, "bus.conf")
+	err := os.WriteFile(conf, []byte(`<busconfig>
+  <type>session</type>
+  <listen>unix:path=`+filepath.Join(dir, "bus")+`</listen>
+  <auth>EXTERNAL</auth>
+  <policy context="default">
+    <allow send_destination="*" eavesdrop="true"/>
+    <allow eavesdrop="true"/>
+    <allow own="*"/>
+  </policy>
+</busconfig>`), 0o644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cmd := exec.Command("dbus-daemon", "--config-file="+conf, "--nofork", "--print-address=1")
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := cmd.Start(); err != nil {
+		t.Skipf("dbus-daemon did not start: %v", err)
+	}
+	t.Cleanup(func() {
+		cmd.Process.Kill()
+		cmd.Wait()
+	})
+	address, err := bufio.NewReader(stdout).ReadString('\n')
+	if err != nil {
+		t.Skipf("dbus-daemon did not start: %v", err)
+	}
+	t.Setenv("DBUS_SESSION_BUS_ADDRESS", strings.TrimSpace(address))
+}
+
+// fakeServer is a notification service that clicks a button of every
+// notification it shows.
+type fakeServer struct {
+	conn   *dbus.Conn
+	closed chan uint32
+
+	mu    sync.Mutex
+	caps  []string
+	click string
+}
+
+func (s *fakeServer) set(caps []string, click string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.caps, s.click = caps, click
+}
+
+func (s *fakeServer) GetCapabilities() ([]string, *dbus.Error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.caps, nil
+}
+
+func (s *fakeServer) Notify(app string, replaces uint32, icon, summary, body string, actions []string, hints map[string]dbus.Variant, timeout int32) (uint32, *dbus.Error) {
+	const id = 7
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.click != "" {
+		go s.conn.Emit(notificationsPath, notificationsName+".ActionInvoked", uint32(id), s.click)
+	}
+	return id, nil
+}
+
+func (s *fakeServer) CloseNotification(id uint32) *dbus.Error {
+	s.closed <- id
+	return nil
+}
+
+func serveNotifications(t *testing.T, s *fakeServer) {
+	t.Helper()
+	conn, err := dbus.ConnectSessionBus()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	s.conn = conn
+	s.closed = make(chan uint32, 4)
+	if err := conn.Export(s, notificationsPath, notificationsName); err != nil {
+		t.Fatal(err)
+	}
+	reply, err := conn.RequestName(notificationsName, dbus.NameFlagDoNotQueue)
+	if err != nil || reply != dbus.RequestNameReplyPrimaryOwner {
+		t.Fatalf("RequestName: %v, %v", reply, err)
+	}
+}
+
+func TestNotifyWithActions(t *testing.T) {
+	startBus(t)
+	server := &fakeServer{}
+	server.set([]string{"body", "actions"}, ActionSnooze)
+	serveNotifications(t, server)
+	actions := []Action{{ActionContinue, "Start break"}, {ActionSnooze, "Snooze 5m"}, {ActionStop, "Stop"}}
+
+	reply := make(chan string, 1)
+	if err := notifyWithActions("Work Complete", "Time for a break!", actions, reply, nil); err != nil {
+		t.Fatalf("notifyWithActions: %v", err)
+	}
+	select {
+	case key := <-reply:
+		if key != ActionSnooze {
+			t.Errorf("Expected the snooze button, got %q", key)
+		}
+	default:
+		t.Error("Expected the clicked button to be sent")
+	}
+
+	// Answering elsewhere withdraws the notification.
+	server.set([]string{"body", "actions"}, "")
+	done := make(chan struct{})
+	errs := make(chan error, 1)
+	go func() { errs <- notifyWithActions("Work Complete", "", actions, reply, done) }()
+	time.Sleep(100 * time.Millisecond)
+	close(done)
+	if err := <-errs; err != nil {
+		t.Fatalf("notifyWithActions: %v", err)
+	}
+	select {
+	case id := <-server.closed:
+		if id != 7 {
+			t.Errorf("Closed notification %d, want 7", id)
+		}
+	case <-time.After(time.Second):
+		t.Error("Expected the notification to be closed")
+	}
+
+	server.set([]string{"body"}, "")
+	if err := notifyWithActions("Work Complete", "", actions, reply, nil); err != errNoActions {
+		t.Errorf("Expected errNoActions without the actions capability, got %v", err)
+	}
+}
@@ -0,0 +1,7 @@
+//go:build !linux
+
+package notify
+
+func notifyWithActions(title, message string, actions []Action, reply chan<- string, done <-chan struct{}) error {
+	return errNoActions
+}
@@ -0,0 +1,36 @@
+package notify
+
+import (
+	"errors"
+
+	"github.com/gen2brain/beeep"
+)
+
+// Desktop shows a system notification when a session completes and for
+// alerts. On Linux, a completed session can offer buttons to answer the
+// prompt that follows it.
+type Desktop struct{}
+
+func (Desktop) Name() string { return "desktop" }
+
+func (Desktop) Notify(ev Event) error {
+	if ev.Type != SessionCompleted && !ev.IsAlert() {
+		return nil
+	}
+	title, message := ev.Text()
+	if len(ev.Actions) > 0 {
+		Go("desktop", func() error {
+			err := notifyWithActions(title, message, ev.Actions, ev.Reply, ev.Done)
+			if errors.Is(err, errNoActions) {
+				return beeep.Notify(title, message, "")
+			}
+			return err
+		})
+		return nil
+	}
+	// Run notification asynchronously to avoid blocking
+	Go("desktop", func() error {
+		return beeep.Notify(title, message, "")
+	})
+	return nil
+}
@@ -0,0 +1,277 @@
+package notify
+
+import (
+	"fmt"
+	"os"
+	"slices"
+	"sync"
+	"time"
+
+	"termidoro/timer"
+)
+
+// EventType identifies what happened to a session.
+type EventType string
+
+const (
+	SessionStarted   EventType = "session_started"
+	SessionCompleted EventType = "session_completed"
+	SessionSkipped   EventType = "session_skipped"
+	SessionCancelled EventType = "session_cancelled"
+	// SessionVoided ends a work session abandoned for too many
+	// interruptions.
+	SessionVoided EventType = "session_voided"
+	// SessionWarning and SessionMilestone are the alerts raised while a
+	// session runs: some time before its end, and at a share of its length.
+	SessionWarning   EventType = "session_warning"
+	SessionMilestone EventType = "session_milestone"
+	// SessionTick is sent for every second that counts down.
+	SessionTick EventType = "session_tick"
+)
+
+// Event is delivered to every enabled notifier.
+type Event struct {
+	Type EventType
+	// Session is a snapshot of the session the event is about.
+	Session timer.Session
+	// Number is the 1-based position of the session in the run.
+	Number int
+	Cycle  int
+	Time   time.Time
+	// Remaining is the time left in the session, for warnings and ticks.
+	Remaining time.Duration
+	// Alert is the text of a warning or milestone, e.g. "2 minutes left".
+	Alert string
+	// CompletedToday counts today's completed work sessions.
+	CompletedToday int
+	// Next and NextDuration describe the phase that follows the session.
+	Next         timer.SessionType
+	NextDuration time.Duration
+	// Title and Message override the default notification text.
+	Title   string
+	Message string
+	// Actions are buttons to offer on the desktop notification. The key of
+	// the one clicked is sent to Reply; closing Done withdraws them.
+	Actions []Action
+	Reply   chan<- string
+	Done    <-chan struct{}
+	// Muted is set by the policy when sounds are held back, for visual
+	// notifiers that would also make one.
+	Muted bool
+}
+
+// Text returns the title and message to show for the event.
+func (e Event) Text() (title, message string) {
+	title, message = defaultText(e)
+	if e.Title != "" {
+		title = e.Title
+	}
+	if e.Message != "" {
+		message = e.Message
+	}
+	return title, message
+}
+
+func defaultText(e Event) (string, string) {
+	switch e.Type {
+	case SessionStarted:
+		if e.Session.Type.IsBreak() {
+			return "Break Started", "Step away for a bit."
+		}
+		return "Work Started", "Time to focus."
+	case SessionCancelled:
+		return "Session Cancelled", "The timer was stopped."
+	case SessionVoided:
+		return "Pomodoro Voided", "Too many interruptions."
+	case SessionWarning:
+		return alertTitle(e, "Almost Done"), "Time to wrap up."
+	case SessionMilestone:
+		return alertTitle(e, "Milestone"), "Keep going."
+	}
+	if e.Session.Type.IsBreak() {
+		return "Break Complete", "Ready for another session?"
+	}
+	return "Work Complete", "Time for a break!"
+}
+
+func alertTitle(e Event, fallback string) string {
+	if e.Alert != "" {
+		return e.Alert
+	}
+	return fallback
+}
+
+// Notifier is a notification backend.
+type Notifier interface {
+	// Name identifies the backend, e.g. "desktop" or "sound".
+	Name() string
+	// Notify delivers the event. It must not block for long; slow work
+	// belongs in a goroutine tracked with Go.
+	Notify(Event) error
+}
+
+type entry struct {
+	notifier Notifier
+	enabled  bool
+}
+
+// Registry fans events out to its enabled notifiers. The zero value and a
+// nil *Registry are both usable and deliver nothing.
+type Registry struct {
+	mu      sync.Mutex
+	entries []entry
+	texts   *Texts
+	policy  *Policy
+}
+
+func NewRegistry(notifiers ...Notifier) *Registry {
+	r := &Registry{}
+	for _, n := range notifiers {
+		r.Register(n)
+	}
+	return r
+}
+
+// Register adds an enabled notifier, replacing any with the same name.
+func (r *Registry) Register(n Notifier) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for i, e := range r.entries {
+		if e.notifier.Name() == n.Name() {
+			r.entries[i] = entry{n, true}
+			return
+		}
+	}
+	r.entries = append(r.entries, entry{n, true})
+}
+
+// SetTexts sets the templates for notification titles and bodies.
+func (r *Registry) SetTexts(t *Texts) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.texts = t
+}
+
+// SetPolicy sets the policy that holds notifications back, e.g. during
+// quiet hours. Without one, every enabled notifier delivers.
+func (r *Registry) SetPolicy(p *Policy) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.policy = p
+}
+
+// SetEnabled turns the named notifier on or off. It reports whether a
+// notifier with that name is registered.
+func (r *Registry) SetEnabled(name string, enabled bool) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for i, e := range r.entries {
+		if e.notifier.Name() == name {
+			r.entries[i].enabled = enabled
+			return true
+		}
+	}
+	return false
+}
+
+// Enabled reports whether the named notifier is registered and enabled.
+func (r *Registry) Enabled(name string) bool {
+	if r == nil {
+		return false
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, e := range r.entries {
+		if e.notifier.Name() == name {
+			return e.enabled
+		}
+	}
+	return false
+}
+
+// IsAlert reports whether the event is a warning or milestone.
+func (e Event) IsAlert() bool {
+	return e.Type == SessionWarning || e.Type == SessionMilestone
+}
+
+// Notify delivers ev to every enabled notifier in registration order.
+// Failures are reported as warnings.
+func (r *Registry) Notify(ev Event) {
+	r.NotifyVia(ev, nil)
+}
+
+// NotifyVia delivers ev like Notify, but only to the named notifiers. A nil
+// list means all of them.
+func (r *Registry) NotifyVia(ev Event, names []string) {
+	if r == nil {
+		return
+	}
+	if ev.Time.IsZero() {
+		ev.Time = time.Now()
+	}
+	r.mu.Lock()
+	entries := append([]entry(nil), r.entries...)
+	texts := r.texts
+	policy := r.policy
+	r.mu.Unlock()
+
+	mode := ModeAll
+	if policy != nil {
+		mode = policy.Current()
+	}
+	ev.Muted = !allows(mode, KindAudible)
+
+	if texts != nil {
+		title, message, err := texts.render(ev)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: Could not render notification text: %v\n", err)
+		}
+		if title != "" {
+			ev.Title = title
+		}
+		if message != "" {
+			ev.Message = message
+		}
+	}
+
+	for _, e := range entries {
+		if !e.enabled || (names != nil && !slices.Contains(names, e.notifier.Name())) {
+			continue
+		}
+		if !allows(mode, kindOf(e.notifier)) {
+			continue
+		}
+		if err := e.notifier.Notify(ev); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: %s notification failed: %v\n", e.notifier.Name(), err)
+		}
+	}
+}
+
+// pending tracks background deliveries so they can finish before exit.
+var pending sync.WaitGroup
+
+// Go runs f in the background, reporting an error as a warning from the
+// named notifier.
+func Go(name string, f func() error) {
+	pending.Add(1)
+	go func() {
+		defer pending.Done()
+		if err := f(); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: %s notification failed: %v\n", name, err)
+		}
+	}()
+}
+
+// Wait gives background deliveries up to limit to finish before the process
+// exits.
+func Wait(limit time.Duration) {
+	done := make(chan struct{})
+	go func() {
+		pending.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(limit):
+	}
+}
@@ -0,0 +1,84 @@
+package notify
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"testing"
+
+	"termidoro/timer"
+)
+
+type fakeNotifier struct {
+	name   string
+	events []Event
+	err    error
+}
+
+func (f *fakeNotifier) Name() string { return f.name }
+
+func (f *fakeNotifier) Notify(ev Event) error {
+	f.events = append(f.events, ev)
+	return f.err
+}
+
+func TestRegistry(t *testing.T) {
+	first := &fakeNotifier{name: "first", err: errors.New("offline")}
+	second := &fakeNotifier{name: "second"}
+	registry := NewRegistry(first, second)
+
+	registry.Notify(Event{Type: SessionStarted})
+	if len(first.events) != 1 || len(second.events) != 1 {
+		t.Fatalf("Expected both notifiers to get the event, got %d and %d", len(first.events), len(second.events))
+	}
+	if first.events[0].Time.IsZero() {
+		t.Error("Expected the event time to be set")
+	}
+
+	if !registry.SetEnabled("first", false) {
+		t.Error("Expected first to be registered")
+	}
+	if registry.SetEnabled("missing", false) {
+		t.Error("Expected missing not to be registered")
+	}
+	registry.Notify(Event{Type: SessionCompleted})
+	if len(first.events) != 1 || len(second.events) != 2 {
+		t.Errorf("Expected only second to get the event, got %d and %d", len(first.events), len(second.events))
+	}
+	if registry.Enabled("first") || !registry.Enabled("second") {
+		t.Error("Unexpected enabled state")
+	}
+
+	replacement := &fakeNotifier{name: "second"}
+	registry.Register(replacement)
+	registry.Notify(Event{Type: SessionCancelled})
+	if len(second.events) != 2 || len(replacement.events) != 1 {
+		t.Error("Expected Register to replace a notifier with the same name")
+	}
+
+	var nilRegistry *Registry
+	nilRegistry.Notify(Event{Type: SessionStarted})
+}
+
+func TestEventText(t *testing.T) {
+	work := Event{Type: SessionCompleted, Session: timer.Session{Type: timer.WORK}}
+	if title, message := work.Text(); title != "Work Complete" || message != "Time for a break!" {
+		t.Errorf("Unexpected work text: %q, %q", title, message)
+	}
+	long := Event{Type: SessionCompleted, Session: timer.Session{Type: timer.LONG_BREAK}, Message: "Stretch"}
+	if title, message := long.Text(); title != "Break Complete" || message != "Stretch" {
+		t.Errorf("Unexpected break text: %q, %q", title, message)
+	}
+}
+
+func TestBell(t *testing.T) {
+	var buf bytes.Buffer
+	SetTerminal(&buf)
+	defer SetTerminal(os.Stdout)
+
+	Bell{}.Notify(Event{Type: SessionStarted})
+	Bell{}.Notify(Event{Type: SessionCompleted})
+	if buf.String() != "\a" {
+		t.Errorf("Expected a single bell, got %q", buf.String())
+	}
+}
@@ -0,0 +1,133 @@
+package notify
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// DefaultPlayers is the order in which Linux audio players are tried.
+var DefaultPlayers = []string{"paplay", "pw-play", "aplay", "ffplay"}
+
+type player struct {
+	// args returns the arguments that play file once at volume (0 to 1)
+	// and exit quietly.
+	args func(file string, volume float64) []string
+	// formats lists the file formats the player understands.
+	formats []string
+}
+
+var players = map[string]player{
+	"paplay": {
+		args: func(file string, volume float64) []string {
+			return []string{fmt.Sprintf("--volume=%d", int(volume*65536)), file}
+		},
+		formats: []string{formatWAV, formatOGG, formatAIFF},
+	},
+	"pw-play": {
+		args: func(file string, volume float64) []string {
+			return []string{fmt.Sprintf("--volume=%.2f", volume), file}
+		},
+		formats: []string{formatWAV, formatOGG, formatAIFF},
+	},
+	// aplay has no volume option; custom files play at their own level.
+	"aplay": {
+		args: func(file string, volume float64) []string {
+			return []string{"-q", file}
+		},
+		formats: []string{formatWAV},
+	},
+	"ffplay": {
+		args: func(file string, volume float64) []string {
+			return []string{"-nodisp", "-autoexit", "-loglevel", "quiet",
+				"-volume", fmt.Sprint(int(volume * 100)), file}
+		},
+		formats: []string{formatWAV, formatOGG, formatAIFF},
+	},
+}
+
+// IsPlayer reports whether name is a supported audio player.
+func IsPlayer(name string) bool {
+	_, ok := players[name]
+	return ok
+}
+
+// PlayerNames lists the supported audio players.
+func PlayerNames() string {
+	return strings.Join(DefaultPlayers, ", ")
+}
+
+var errNoPlayer = errors.New("no audio player found")
+
+var (
+	// workingPlayer remembers the first player that succeeded, so later
+	// sounds skip the ones that failed.
+	workingPlayer string
+	playerMu      sync.Mutex
+)
+
+// playWAV plays WAV data through the first player in order that works.
+func playWAV(data []byte, order []string) error {
+	f, err := os.CreateTemp("", "termidoro-*.wav")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	// The samples are already scaled to the requested volume.
+	return playFile(f.Name(), formatWAV, 1, order)
+}
+
+// playFile plays file at volume through the first player in order that
+// understands format and works. macOS always uses afplay.
+func playFile(file, format string, volume float64, order []string) error {
+	if runtime.GOOS == "darwin" {
+		return exec.Command("afplay", "-v", fmt.Sprintf("%.2f", volume), file).Run()
+	}
+	if len(order) == 0 {
+		order = DefaultPlayers
+	}
+	playerMu.Lock()
+	preferred := workingPlayer
+	playerMu.Unlock()
+	if slices.Contains(order, preferred) {
+		order = append([]string{preferred}, order...)
+	}
+
+	var errs []error
+	tried := map[string]bool{}
+	for _, name := range order {
+		p, ok := players[name]
+		if !ok || tried[name] || !slices.Contains(p.formats, format) {
+			continue
+		}
+		tried[name] = true
+		path, err := exec.LookPath(name)
+		if err != nil {
+			continue
+		}
+		if err := exec.Command(path, p.args(file, volume)...).Run(); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", name, err))
+			continue
+		}
+		playerMu.Lock()
+		workingPlayer = name
+		playerMu.Unlock()
+		return nil
+	}
+	if len(errs) == 0 {
+		return fmt.Errorf("%w for %s files (tried %s)", errNoPlayer, format, strings.Join(order, ", "))
+	}
+	return errors.Join(errs...)
+}
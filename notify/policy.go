@@ -0,0 +1,231 @@
+package notify
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Mode decides which kinds of notifiers may deliver events.
+type Mode string
+
+const (
+	ModeAll        Mode = "all"
+	ModeSoundOnly  Mode = "sound-only"
+	ModeVisualOnly Mode = "visual-only"
+	ModeSilent     Mode = "silent"
+)
+
+var Modes = []Mode{ModeAll, ModeSoundOnly, ModeVisualOnly, ModeSilent}
+
+// ParseMode validates a mode name.
+func ParseMode(name string) (Mode, error) {
+	for _, m := range Modes {
+		if string(m) == name {
+			return m, nil
+		}
+	}
+	names := make([]string, len(Modes))
+	for i, m := range Modes {
+		names[i] = string(m)
+	}
+	return "", fmt.Errorf("unknown notification mode %q (expected one of %s)", name, strings.Join(names, ", "))
+}
+
+// Kind classifies a notifier for the policy.
+type Kind int
+
+const (
+	// KindVisual notifiers show something, like desktop notifications.
+	KindVisual Kind = iota
+	// KindAudible notifiers make a sound.
+	KindAudible
+	// KindIntegration notifiers feed other programs, like webhooks. The
+	// policy never holds them back.
+	KindIntegration
+)
+
+// kinded is implemented by notifiers that are not visual.
+type kinded interface {
+	Kind() Kind
+}
+
+func kindOf(n Notifier) Kind {
+	if k, ok := n.(kinded); ok {
+		return k.Kind()
+	}
+	return KindVisual
+}
+
+func (Sound) Kind() Kind    { return KindAudible }
+func (Bell) Kind() Kind     { return KindAudible }
+func (Webhooks) Kind() Kind { return KindIntegration }
+
+// QuietHours is a daily window, given as offsets from midnight. A window
+// whose end comes before its start runs past midnight.
+type QuietHours struct {
+	Start time.Duration
+	End   time.Duration
+}
+
+// ParseQuietHours parses a window such as "22:00-08:00".
+func ParseQuietHours(window string) (QuietHours, error) {
+	start, end, ok := strings.Cut(window, "-")
+	if !ok {
+		return QuietHours{}, fmt.Errorf("invalid quiet hours %q: expected HH:MM-HH:MM", window)
+	}
+	var q QuietHours
+	for _, part := range []struct {
+		value string
+		dest  *time.Duration
+	}{{start, &q.Start}, {end, &q.End}} {
+		t, err := time.Parse("15:04", strings.TrimSpace(part.value))
+		if err != nil {
+			return QuietHours{}, fmt.Errorf("invalid quiet hours %q: expected HH:MM-HH:MM", window)
+		}
+		*part.dest = time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute
+	}
+	return q, nil
+}
+
+// Contains reports whether t falls inside the window.
+func (q QuietHours) Contains(t time.Time) bool {
+	offset := time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute
+	if q.Start <= q.End {
+		return offset >= q.Start && offset < q.End
+	}
+	return offset >= q.Start || offset < q.End
+}
+
+// Policy decides, at the moment of each event, which notifiers may deliver
+// it: a snooze silences everything, quiet hours switch to QuietMode, and
+// Mode applies otherwise.
+type Policy struct {
+	Mode       Mode
+	QuietHours []QuietHours
+	// QuietMode applies during quiet hours; silent when empty.
+	QuietMode Mode
+	// SnoozePath is the snooze state file; none when empty.
+	SnoozePath string
+	now        func() time.Time
+
+	// The snooze is read again only when the file's modification time
+	// changes, since Current runs for every tick.
+	mu          sync.Mutex
+	snoozeMod   time.Time
+	snoozeUntil time.Time
+}
+
+// Current returns the mode in effect now.
+func (p *Policy) Current() Mode {
+	now := time.Now()
+	if p.now != nil {
+		now = p.now()
+	}
+	if now.Before(p.snoozedUntil()) {
+		return ModeSilent
+	}
+	for _, q := range p.QuietHours {
+		if q.Contains(now) {
+			if p.QuietMode == "" {
+				return ModeSilent
+			}
+			return p.QuietMode
+		}
+	}
+	if p.Mode == "" {
+		return ModeAll
+	}
+	return p.Mode
+}
+
+// snoozedUntil returns the end of the snooze, reading the file only when it
+// has changed since the last call.
+func (p *Policy) snoozedUntil() time.Time {
+	if p.SnoozePath == "" {
+		return time.Time{}
+	}
+	var mod time.Time
+	if info, err := os.Stat(p.SnoozePath); err == nil {
+		mod = info.ModTime()
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if !mod.Equal(p.snoozeMod) {
+		p.snoozeMod = mod
+		p.snoozeUntil, _ = SnoozedUntil(p.SnoozePath)
+	}
+	return p.snoozeUntil
+}
+
+// allows reports whether mode lets a notifier of kind k deliver.
+func allows(mode Mode, k Kind) bool {
+	switch {
+	case k == KindIntegration, mode == ModeAll:
+		return true
+	case mode == ModeSoundOnly:
+		return k == KindAudible
+	case mode == ModeVisualOnly:
+		return k == KindVisual
+	}
+	return false
+}
+
+// SnoozedUntil returns the end of the snooze recorded at path, or the zero
+// time when there is none.
+func SnoozedUntil(path string) (time.Time, error) {
+	if path == "" {
+		return time.Time{}, nil
+	}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return time.Time{}, nil
+	}
+	if err != nil {
+		return time.Time{}, err
+	}
+	return time.Parse(time.RFC3339, strings.TrimSpace(string(data)))
+}
+
+// Snooze silences notifications until the given time. A zero time ends
+// the snooze.
+func Snooze(path string, until time.Time) error {
+	if until.IsZero() {
+		err := os.Remove(path)
+		if errors.Is(err, os.ErrNotExist) {
+			return nil
+		}
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, []byte(until.Format(time.RFC3339)+"\n"), 0o644)
+}
+
+// RunSnooze implements the snooze subcommand on the snooze file at path:
+// arg is a duration, or "off" to end the snooze.
+func RunSnooze(path, arg string) error {
+	if arg == "off" {
+		if err := Snooze(path, time.Time{}); err != nil {
+			return err
+		}
+		fmt.Println("Notifications are back on.")
+		return nil
+	}
+
+	duration, err := time.ParseDuration(arg)
+	if err != nil || duration <= 0 {
+		return fmt.Errorf("invalid snooze duration %q", arg)
+	}
+	until := time.Now().Add(duration)
+	if err := Snooze(path, until); err != nil {
+		return err
+	}
+	fmt.Printf("Notifications snoozed until %s.\n", until.Format("15:04"))
+	return nil
+}
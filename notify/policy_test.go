@@ -0,0 +1,117 @@
+package notify
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestQuietHours(t *testing.T) {
+	night, err := ParseQuietHours("22:00-08:00")
+	if err != nil {
+		t.Fatalf("ParseQuietHours: %v", err)
+	}
+	lunch, err := ParseQuietHours("12:00 - 13:30")
+	if err != nil {
+		t.Fatalf("ParseQuietHours: %v", err)
+	}
+	at := func(hour, minute int) time.Time {
+		return time.Date(2026, 1, 12, hour, minute, 0, 0, time.Local)
+	}
+
+	tests := []struct {
+		q    QuietHours
+		t    time.Time
+		want bool
+	}{
+		{night, at(23, 0), true},
+		{night, at(2, 0), true},
+		{night, at(8, 0), false},
+		{night, at(21, 59), false},
+		{lunch, at(12, 0), true},
+		{lunch, at(13, 29), true},
+		{lunch, at(13, 30), false},
+	}
+	for _, tt := range tests {
+		if got := tt.q.Contains(tt.t); got != tt.want {
+			t.Errorf("%+v.Contains(%s) = %v, want %v", tt.q, tt.t.Format("15:04"), got, tt.want)
+		}
+	}
+
+	for _, bad := range []string{"22:00", "25:00-08:00", "night"} {
+		if _, err := ParseQuietHours(bad); err == nil {
+			t.Errorf("Expected error for %q", bad)
+		}
+	}
+}
+
+func TestPolicy(t *testing.T) {
+	now := time.Date(2026, 1, 12, 10, 0, 0, 0, time.Local)
+	snooze := filepath.Join(t.TempDir(), "snooze")
+	policy := &Policy{
+		Mode:       ModeVisualOnly,
+		QuietHours: []QuietHours{{Start: 9 * time.Hour, End: 9*time.Hour + 30*time.Minute}},
+		QuietMode:  ModeSoundOnly,
+		SnoozePath: snooze,
+		now:        func() time.Time { return now },
+	}
+	if got := policy.Current(); got != ModeVisualOnly {
+		t.Errorf("Expected the configured mode, got %s", got)
+	}
+
+	now = now.Add(-45 * time.Minute)
+	if got := policy.Current(); got != ModeSoundOnly {
+		t.Errorf("Expected the quiet mode during quiet hours, got %s", got)
+	}
+
+	if err := Snooze(snooze, now.Add(time.Hour)); err != nil {
+		t.Fatal(err)
+	}
+	if got := policy.Current(); got != ModeSilent {
+		t.Errorf("Expected silence while snoozed, got %s", got)
+	}
+	if err := Snooze(snooze, time.Time{}); err != nil {
+		t.Fatal(err)
+	}
+	if until, err := SnoozedUntil(snooze); err != nil || !until.IsZero() {
+		t.Errorf("Expected the snooze to end, got %v, %v", until, err)
+	}
+	if got := policy.Current(); got != ModeSoundOnly {
+		t.Errorf("Expected the quiet mode once the snooze ended, got %s", got)
+	}
+}
+
+func TestRegistryPolicy(t *testing.T) {
+	visual := &fakeNotifier{name: "visual"}
+	audible := &audibleNotifier{fakeNotifier{name: "audible"}}
+	registry := NewRegistry(visual, audible, Webhooks{})
+	policy := &Policy{Mode: ModeSoundOnly}
+	registry.SetPolicy(policy)
+
+	registry.Notify(Event{Type: SessionCompleted})
+	if len(visual.events) != 0 || len(audible.events) != 1 {
+		t.Errorf("Expected only the audible notifier in sound-only mode, got %d and %d", len(visual.events), len(audible.events))
+	}
+
+	policy.Mode = ModeSilent
+	registry.Notify(Event{Type: SessionCompleted})
+	if len(visual.events) != 0 || len(audible.events) != 1 {
+		t.Error("Expected nothing to be delivered in silent mode")
+	}
+
+	policy.Mode = ModeVisualOnly
+	registry.Notify(Event{Type: SessionCompleted})
+	if len(visual.events) != 1 || !visual.events[0].Muted || audible.events[0].Muted {
+		t.Error("Expected the visual notifier to be told to stay quiet in visual-only mode")
+	}
+
+	if !allows(ModeSilent, KindIntegration) {
+		t.Error("Expected integrations to ignore the policy")
+	}
+}
+
+type audibleNotifier struct {
+	fakeNotifier
+}
+
+func (*audibleNotifier) Kind() Kind { return KindAudible }
@@ -1,95 +1,216 @@
 package notify
 
 import (
+	"errors"
 	"fmt"
 	"os"
 	"os/exec"
 	"runtime"
+	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/gen2brain/beeep"
-)
 
-var soundEnabled = true
+	"termidoro/timer"
+)
 
-func SetSoundEnabled(enabled bool) {
-	soundEnabled = enabled
+// Sound plays a chime when a session completes and a tone for alerts. It
+// can also tick during sessions and beep through the last seconds.
+type Sound struct {
+	// Players is the order in which Linux audio players are tried;
+	// DefaultPlayers when empty.
+	Players []string
+	// Sounds overrides the file and volume per sound event.
+	Sounds map[string]SoundSetting
+	// Tick ticks every second of a work session, and of breaks too when
+	// TickBreaks is set.
+	Tick       bool
+	TickBreaks bool
+	// Countdown beeps every second of the last Countdown seconds.
+	Countdown int
 }
 
-func PlayCompletionSound() error {
-	return beeep.Notify("Pomodoro Complete", "Time is up!", "")
+func (Sound) Name() string { return "sound" }
+
+func (s Sound) Notify(ev Event) error {
+	var key string
+	switch {
+	case ev.Type == SessionTick:
+		return s.tick(ev)
+	case ev.IsAlert():
+		key = SoundWarning
+	case ev.Type != SessionCompleted:
+		return nil
+	case ev.Session.Type == timer.LONG_BREAK:
+		key = SoundLongBreakComplete
+	case ev.Session.Type == timer.BREAK:
+		key = SoundBreakComplete
+	default:
+		key = SoundWorkComplete
+	}
+	setting := s.setting(key)
+	// Visual feedback first (always)
+	flashTerminal()
+	if setting.File != "" {
+		return s.playCustomSound(setting)
+	}
+	if key == SoundWarning {
+		return s.playTones(alertTone, setting.Volume)
+	}
+	if ev.Session.Type.IsBreak() {
+		return s.playBreakSound(setting.Volume)
+	}
+	return s.playWorkSound(setting.Volume)
 }
 
-func PlayWorkCompleteSound() error {
-	if soundEnabled {
-		if err := playWorkSound(); err != nil {
-			fmt.Fprintf(os.Stderr, "Warning: Could not play work completion sound: %v\n", err)
-		}
+// ticksOff is set once a tick or countdown beep fails to play. They come
+// every second, so the failure is reported once and ticking stops instead.
+var ticksOff atomic.Bool
+
+// tick plays the countdown beep or the tick for one second of a session.
+func (s Sound) tick(ev Event) error {
+	var key string
+	var tones []tone
+	switch {
+	case ev.Remaining <= 0 || ticksOff.Load():
+		return nil
+	case ev.Remaining <= time.Duration(s.Countdown)*time.Second:
+		key, tones = SoundCountdown, countdownBeep
+	case s.Tick && (!ev.Session.Type.IsBreak() || s.TickBreaks):
+		key, tones = SoundTick, tickClick
+	default:
+		return nil
+	}
+	setting := s.setting(key)
+	file, format, volume := setting.File, formatWAV, setting.Volume
+	var err error
+	switch {
+	case file != "":
+		format, err = soundFormat(file)
+	case runtime.GOOS != "linux" && runtime.GOOS != "darwin":
+		return s.playTones(tones, volume)
+	default:
+		// Ticks come every second, so their WAV is written once and reused.
+		file, err = toneFile(key, tones, volume)
+		volume = 1
 	}
-	// Run notification asynchronously to avoid blocking
-	go func() {
-		beeep.Notify("Work Complete", "Time for a break!", "")
-	}()
+	if err != nil {
+		return stopTicks(err)
+	}
+	Go("sound", func() error {
+		return stopTicks(playFile(file, format, volume, s.Players))
+	})
 	return nil
 }
 
-func PlayBreakCompleteSound() error {
-	if soundEnabled {
-		if err := playBreakSound(); err != nil {
-			fmt.Fprintf(os.Stderr, "Warning: Could not play break completion sound: %v\n", err)
-		}
+// stopTicks turns ticking off after the first failure, which is the only
+// one reported.
+func stopTicks(err error) error {
+	if err == nil || ticksOff.Swap(true) {
+		return nil
 	}
-	// Run notification asynchronously to avoid blocking
-	go func() {
-		beeep.Notify("Break Complete", "Ready for another session?", "")
-	}()
-	return nil
+	return fmt.Errorf("%w; ticking turned off", err)
 }
 
-func flashTerminal() {
-	// Instant visual feedback without blocking delay
-	fmt.Print("\033[5m")  // Inverse video
-	fmt.Print("\033[25m") // Normal video
+// toneFiles holds the WAV files written by toneFile until RemoveToneFiles.
+var toneFiles = struct {
+	sync.Mutex
+	paths map[toneFileKey]string
+}{paths: map[toneFileKey]string{}}
+
+type toneFileKey struct {
+	key    string
+	volume float64
 }
 
-func playWorkSound() error {
+// toneFile returns a WAV file of tones at volume for the sound event key,
+// writing it on first use.
+func toneFile(key string, tones []tone, volume float64) (string, error) {
+	toneFiles.Lock()
+	defer toneFiles.Unlock()
+	k := toneFileKey{key, volume}
+	if path, ok := toneFiles.paths[k]; ok {
+		return path, nil
+	}
+	f, err := os.CreateTemp("", "termidoro-"+key+"-*.wav")
+	if err != nil {
+		return "", err
+	}
+	_, err = f.Write(encodeWAV(synthesize(tones, 0.5*volume)))
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		os.Remove(f.Name())
+		return "", err
+	}
+	toneFiles.paths[k] = f.Name()
+	return f.Name(), nil
+}
+
+// RemoveToneFiles deletes the WAV files written for ticks and countdown
+// beeps. It is called when the run ends.
+func RemoveToneFiles() {
+	toneFiles.Lock()
+	defer toneFiles.Unlock()
+	for k, path := range toneFiles.paths {
+		os.Remove(path)
+		delete(toneFiles.paths, k)
+	}
+}
+
+// setting returns the sound for key. Long breaks fall back to the break
+// sound, and events without a setting play the chime at full volume.
+func (s Sound) setting(key string) SoundSetting {
+	if setting, ok := s.Sounds[key]; ok {
+		return setting
+	}
+	if key == SoundLongBreakComplete {
+		return s.setting(SoundBreakComplete)
+	}
+	return SoundSetting{Volume: 1}
+}
+
+func (s Sound) playWorkSound(volume float64) error {
 	switch runtime.GOOS {
 	case "darwin":
-		return playMacOSSound("glass")
+		return playMacOSSound("/System/Library/Sounds/Glass.aiff", volume)
 	case "linux":
-		return playLinuxSound(1000, 200)
+		return s.playTones(workChime, volume)
 	default:
 		return beeep.Beep(880, 200)
 	}
 }
 
-func playBreakSound() error {
+func (s Sound) playBreakSound(volume float64) error {
 	switch runtime.GOOS {
 	case "darwin":
-		return playMacOSSound("purr")
+		return playMacOSSound("/System/Library/Sounds/Purr.aiff", volume)
 	case "linux":
-		return playLinuxSound(600, 150)
+		return s.playTones(breakChime, volume)
 	default:
 		return beeep.Beep(440, 150)
 	}
 }
 
-func playMacOSSound(soundType string) error {
-	// Visual feedback first (always)
-	flashTerminal()
-
-	// Play appropriate system sound instantly using afplay
-	var soundFile string
-	switch soundType {
-	case "glass":
-		soundFile = "/System/Library/Sounds/Glass.aiff"
-	case "purr":
-		soundFile = "/System/Library/Sounds/Purr.aiff"
-	default:
-		soundFile = "/System/Library/Sounds/Glass.aiff"
+func (s Sound) playCustomSound(setting SoundSetting) error {
+	if runtime.GOOS == "darwin" {
+		return playMacOSSound(setting.File, setting.Volume)
+	}
+	format, err := soundFormat(setting.File)
+	if err != nil {
+		return err
 	}
+	Go("sound", func() error {
+		return quietNoPlayer(playFile(setting.File, format, setting.Volume, s.Players))
+	})
+	return nil
+}
 
+func playMacOSSound(soundFile string, volume float64) error {
 	// Play sound using afplay in background for instant UI response
-	afplayCmd := exec.Command("afplay", soundFile)
+	afplayCmd := exec.Command("afplay", "-v", fmt.Sprintf("%.2f", volume), soundFile)
 	err := afplayCmd.Start()
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Warning: afplay failed to start %s: %v\n", soundFile, err)
@@ -99,13 +220,29 @@ func playMacOSSound(soundType string) error {
 	return nil
 }
 
-func playLinuxSound(frequency, duration int) error {
-	flashTerminal()
+// warnedNoPlayer keeps a missing audio player from being reported after
+// every session.
+var warnedNoPlayer atomic.Bool
 
-	durationSec := float64(duration) / 1000.0
-	cmd := exec.Command("ffplay", "-nodisp", "-autoexit",
-		"-t", fmt.Sprintf("%.2f", durationSec),
-		"-freq", fmt.Sprintf("%d", frequency))
+// playTones plays synthesized tones through the Linux players or afplay,
+// and falls back to a plain beep elsewhere.
+func (s Sound) playTones(tones []tone, volume float64) error {
+	if runtime.GOOS != "linux" && runtime.GOOS != "darwin" {
+		return beeep.Beep(tones[0].frequency, int(tones[0].duration.Milliseconds()))
+	}
+	data := encodeWAV(synthesize(tones, 0.5*volume))
+	// Players run until the chime ends; keep the countdown moving meanwhile.
+	Go("sound", func() error {
+		return quietNoPlayer(playWAV(data, s.Players))
+	})
+	return nil
+}
 
-	return cmd.Run()
+// quietNoPlayer passes err through, except that a missing player is only
+// reported once.
+func quietNoPlayer(err error) error {
+	if errors.Is(err, errNoPlayer) && warnedNoPlayer.Swap(true) {
+		return nil
+	}
+	return err
 }
@@ -0,0 +1,173 @@
+package notify
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"termidoro/timer"
+)
+
+func TestSoundFormat(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string][]byte{
+		"chime.wav":  encodeWAV([]int16{0, 1}),
+		"chime.ogg":  []byte("OggS\x00\x02\x00\x00\x00\x00\x00\x00"),
+		"chime.aiff": []byte("FORM\x00\x00\x00\x10AIFF"),
+		"notes.txt":  []byte("not a sound at all"),
+		"short.wav":  []byte("RIFF"),
+	}
+	for name, data := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), data, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		file    string
+		want    string
+		wantErr bool
+	}{
+		{"chime.wav", formatWAV, false},
+		{"chime.ogg", formatOGG, false},
+		{"chime.aiff", formatAIFF, false},
+		{"notes.txt", "", true},
+		{"short.wav", "", true},
+		{"missing.wav", "", true},
+	}
+	for _, tt := range tests {
+		got, err := soundFormat(filepath.Join(dir, tt.file))
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("soundFormat(%s) = %q, %v; want %q", tt.file, got, err, tt.want)
+		}
+	}
+
+	if runtime.GOOS == "linux" {
+		if err := CheckSoundFile(filepath.Join(dir, "chime.ogg")); err != nil {
+			t.Errorf("Expected OGG to be playable, got %v", err)
+		}
+	}
+	if err := CheckSoundFile(filepath.Join(dir, "missing.wav")); err == nil {
+		t.Error("Expected error for missing file")
+	}
+	if err := CheckSoundFile(dir); err == nil {
+		t.Error("Expected error for directory")
+	}
+}
+
+func TestSoundSetting(t *testing.T) {
+	s := Sound{Sounds: map[string]SoundSetting{
+		SoundBreakComplete: {File: "break.wav", Volume: 0.4},
+	}}
+	if got := s.setting(SoundLongBreakComplete); got.File != "break.wav" || got.Volume != 0.4 {
+		t.Errorf("Expected long break to fall back to the break sound, got %+v", got)
+	}
+	if got := s.setting(SoundWorkComplete); got.File != "" || got.Volume != 1 {
+		t.Errorf("Expected the default chime at full volume, got %+v", got)
+	}
+	if got := s.setting(SoundWarning); got.File != "" || got.Volume != 1 {
+		t.Errorf("Expected the alert tone at full volume, got %+v", got)
+	}
+}
+
+func TestSoundTick(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("player scripts stand in for the Linux players")
+	}
+	dir := t.TempDir()
+	log := filepath.Join(dir, "played")
+	script := "#!/bin/sh\nwc -c < \"$2\" >> " + log + "\n"
+	if err := os.WriteFile(filepath.Join(dir, "aplay"), []byte(script), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", dir+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	work := timer.Session{Type: timer.WORK}
+	brk := timer.Session{Type: timer.BREAK}
+	s := Sound{Players: []string{"aplay"}, Tick: true, Countdown: 3}
+	for _, ev := range []Event{
+		{Type: SessionTick, Session: work, Remaining: 10 * time.Second},
+		{Type: SessionTick, Session: brk, Remaining: 10 * time.Second},
+		{Type: SessionTick, Session: brk, Remaining: 2 * time.Second},
+		{Type: SessionTick, Session: work, Remaining: 0},
+	} {
+		if err := s.Notify(ev); err != nil {
+			t.Fatalf("Notify: %v", err)
+		}
+	}
+	Wait(5 * time.Second)
+
+	data, err := os.ReadFile(log)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tick := len(encodeWAV(synthesize(tickClick, 1)))
+	beep := len(encodeWAV(synthesize(countdownBeep, 1)))
+	// Playback runs in the background, so the order is not fixed.
+	got := strings.Fields(string(data))
+	slices.Sort(got)
+	want := []string{strconv.Itoa(tick), strconv.Itoa(beep)}
+	slices.Sort(want)
+	if !slices.Equal(got, want) {
+		t.Errorf("Expected a tick and a countdown beep %v, got %v", want, got)
+	}
+}
+
+func TestSoundTickFailure(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("player scripts stand in for the Linux players")
+	}
+	defer ticksOff.Store(false)
+	dir := t.TempDir()
+	log := filepath.Join(dir, "played")
+	script := "#!/bin/sh\necho x >> " + log + "\nexit 1\n"
+	if err := os.WriteFile(filepath.Join(dir, "aplay"), []byte(script), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", dir+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	s := Sound{Players: []string{"aplay"}, Tick: true}
+	tick := Event{Type: SessionTick, Session: timer.Session{Type: timer.WORK}, Remaining: time.Minute}
+	for range 3 {
+		if err := s.Notify(tick); err != nil {
+			t.Fatalf("Notify: %v", err)
+		}
+		Wait(5 * time.Second)
+	}
+
+	data, err := os.ReadFile(log)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := strings.Count(string(data), "x"); n != 1 || !ticksOff.Load() {
+		t.Errorf("Expected ticking to stop after the first failure, played %d times", n)
+	}
+}
+
+func TestToneFile(t *testing.T) {
+	t.Setenv("TMPDIR", t.TempDir())
+	defer RemoveToneFiles()
+
+	first, err := toneFile(SoundTick, tickClick, 0.5)
+	if err != nil {
+		t.Fatalf("toneFile: %v", err)
+	}
+	again, _ := toneFile(SoundTick, tickClick, 0.5)
+	louder, _ := toneFile(SoundTick, tickClick, 1)
+	if again != first || louder == first {
+		t.Errorf("Expected the file to be reused per volume, got %s, %s and %s", first, again, louder)
+	}
+	if format, err := soundFormat(first); err != nil || format != formatWAV {
+		t.Errorf("Expected a WAV file, got %q, %v", format, err)
+	}
+
+	RemoveToneFiles()
+	if _, err := os.Stat(first); !os.IsNotExist(err) {
+		t.Errorf("Expected %s to be removed, got %v", first, err)
+	}
+}
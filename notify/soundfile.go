@@ -0,0 +1,97 @@
+package notify
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"runtime"
+)
+
+// Sound events that can have their own file and volume.
+const (
+	SoundWorkComplete      = "work_complete"
+	SoundBreakComplete     = "break_complete"
+	SoundLongBreakComplete = "long_break_complete"
+	// SoundWarning plays for pre-end warnings and milestones.
+	SoundWarning = "warning"
+	// SoundTick and SoundCountdown play every second while ticking or
+	// counting down is enabled.
+	SoundTick      = "tick"
+	SoundCountdown = "countdown"
+)
+
+// SoundEvents lists the sound events in the order they are documented.
+var SoundEvents = []string{
+	SoundWorkComplete, SoundBreakComplete, SoundLongBreakComplete,
+	SoundWarning, SoundTick, SoundCountdown,
+}
+
+// SoundSetting overrides the sound played for one event.
+type SoundSetting struct {
+	// File replaces the built-in chime when set.
+	File string
+	// Volume ranges from 0 (muted) to 1 (full).
+	Volume float64
+}
+
+const (
+	formatWAV  = "WAV"
+	formatOGG  = "OGG"
+	formatAIFF = "AIFF"
+)
+
+// soundFormat identifies a WAV, OGG or AIFF file from its header.
+func soundFormat(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	header := make([]byte, 12)
+	if _, err := io.ReadFull(f, header); err != nil {
+		return "", fmt.Errorf("%s is not a WAV, OGG or AIFF file", path)
+	}
+	switch {
+	case bytes.HasPrefix(header, []byte("RIFF")) && bytes.Equal(header[8:12], []byte("WAVE")):
+		return formatWAV, nil
+	case bytes.HasPrefix(header, []byte("OggS")):
+		return formatOGG, nil
+	case bytes.HasPrefix(header, []byte("FORM")) &&
+		(bytes.Equal(header[8:12], []byte("AIFF")) || bytes.Equal(header[8:12], []byte("AIFC"))):
+		return formatAIFF, nil
+	}
+	return "", fmt.Errorf("%s is not a WAV, OGG or AIFF file", path)
+}
+
+// CheckSoundFile reports whether path exists and holds a sound this
+// platform can play.
+func CheckSoundFile(path string) error {
+	info, err := os.Stat(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("sound file %s does not exist", path)
+	}
+	if err != nil {
+		return err
+	}
+	if info.IsDir() {
+		return fmt.Errorf("sound file %s is a directory", path)
+	}
+
+	format, err := soundFormat(path)
+	if err != nil {
+		return err
+	}
+	switch runtime.GOOS {
+	case "darwin":
+		if format == formatOGG {
+			return fmt.Errorf("%s: afplay cannot play OGG files, use WAV or AIFF", path)
+		}
+	case "linux":
+	default:
+		return fmt.Errorf("custom sound files are not supported on %s", runtime.GOOS)
+	}
+	return nil
+}
@@ -0,0 +1,163 @@
+package notify
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// terminal receives the escape sequences used for visual feedback.
+var terminal io.Writer = os.Stdout
+
+// SetTerminal redirects visual feedback, e.g. to io.Discard when stdout is
+// not a terminal.
+func SetTerminal(w io.Writer) {
+	terminal = w
+}
+
+// Bell rings the terminal bell when a session completes and for alerts.
+type Bell struct{}
+
+func (Bell) Name() string { return "bell" }
+
+func (Bell) Notify(ev Event) error {
+	if ev.Type != SessionCompleted && !ev.IsAlert() {
+		return nil
+	}
+	_, err := fmt.Fprint(terminal, "\a")
+	return err
+}
+
+// Escape sequences a Terminal can notify with.
+const (
+	// ProtocolOSC9 is understood by iTerm2, WezTerm, kitty, Ghostty and
+	// Windows Terminal.
+	ProtocolOSC9 = "osc9"
+	// ProtocolOSC777 is understood by VTE-based terminals, foot and rxvt.
+	ProtocolOSC777 = "osc777"
+	// ProtocolBell only rings the bell, for terminals without either.
+	ProtocolBell = "bell"
+)
+
+// TerminalProtocols lists the protocols in the order they are documented.
+var TerminalProtocols = []string{ProtocolOSC9, ProtocolOSC777, ProtocolBell}
+
+// Terminal notifies through the terminal itself with OSC escape sequences.
+// Unlike desktop notifications, these reach the user's own machine from an
+// SSH session or from inside tmux.
+type Terminal struct {
+	// Protocol is one of TerminalProtocols.
+	Protocol string
+	// Progress shows the OSC 9;4 progress indicator while a session runs.
+	Progress bool
+	// Tmux wraps the sequences for tmux to pass them on to the outer
+	// terminal; tmux needs "set -g allow-passthrough on".
+	Tmux bool
+}
+
+func (Terminal) Name() string { return "terminal" }
+
+func (t Terminal) Notify(ev Event) error {
+	switch ev.Type {
+	case SessionTick:
+		return t.progress(ev)
+	case SessionSkipped, SessionCancelled, SessionVoided:
+		return t.clearProgress()
+	case SessionCompleted:
+		if err := t.clearProgress(); err != nil {
+			return err
+		}
+	case SessionWarning, SessionMilestone:
+	default:
+		return nil
+	}
+
+	title, message := ev.Text()
+	var seq string
+	switch t.Protocol {
+	case ProtocolOSC777:
+		seq = fmt.Sprintf("\033]777;notify;%s;%s\a", oscText(title, ";"), oscText(message, ""))
+	case ProtocolOSC9:
+		seq = fmt.Sprintf("\033]9;%s: %s\a", oscText(title, ""), oscText(message, ""))
+	}
+	if seq != "" {
+		if err := t.write(seq); err != nil {
+			return err
+		}
+	}
+	if ev.Muted {
+		return nil
+	}
+	// The bell needs no passthrough: tmux rings it in the outer terminal
+	// and flags the window it came from.
+	_, err := fmt.Fprint(terminal, "\a")
+	return err
+}
+
+// progress sets the progress indicator to the share of the session done.
+func (t Terminal) progress(ev Event) error {
+	if !t.Progress || ev.Session.Duration <= 0 || ev.Remaining <= 0 {
+		return nil
+	}
+	done := 100 * (ev.Session.Duration - ev.Remaining) / ev.Session.Duration
+	return t.write(fmt.Sprintf("\033]9;4;1;%d\a", int(done)))
+}
+
+func (t Terminal) clearProgress() error {
+	if !t.Progress {
+		return nil
+	}
+	return t.write("\033]9;4;0\a")
+}
+
+// write sends an escape sequence, wrapped for tmux passthrough if needed.
+func (t Terminal) write(seq string) error {
+	if t.Tmux {
+		seq = "\033Ptmux;" + strings.ReplaceAll(seq, "\033", "\033\033") + "\033\\"
+	}
+	_, err := fmt.Fprint(terminal, seq)
+	return err
+}
+
+// oscText strips the control characters that would end an OSC sequence
+// early, and the separators given in drop.
+func oscText(s, drop string) string {
+	return strings.Map(func(r rune) rune {
+		if r < 0x20 || r == 0x7f || strings.ContainsRune(drop, r) {
+			return ' '
+		}
+		return r
+	}, s)
+}
+
+// DetectTerminal picks the protocol for the terminal described by the
+// environment, and whether tmux sits in between. Progress is only turned
+// on for terminals known to draw it, since older ones show OSC 9;4 as a
+// notification.
+func DetectTerminal(getenv func(string) string) Terminal {
+	t := Terminal{Protocol: ProtocolOSC9, Tmux: getenv("TMUX") != ""}
+	term := getenv("TERM")
+	if getenv("VTE_VERSION") != "" || strings.HasPrefix(term, "foot") || strings.HasPrefix(term, "rxvt") {
+		t.Protocol = ProtocolOSC777
+	}
+	t.Progress = getenv("WT_SESSION") != "" || getenv("ConEmuPID") != "" || getenv("TERM_PROGRAM") == "ghostty"
+	return t
+}
+
+// RemoteSession reports whether termidoro runs over SSH, where desktop
+// notifications would not reach the user's screen.
+func RemoteSession(getenv func(string) string) bool {
+	for _, name := range []string{"SSH_CONNECTION", "SSH_CLIENT", "SSH_TTY"} {
+		if getenv(name) != "" {
+			return true
+		}
+	}
+	return false
+}
+
+func flashTerminal() {
+	// Instant visual feedback without blocking delay
+	fmt.Fprint(terminal, "\033[5m")  // Inverse video
+	fmt.Fprint(terminal, "\033[25m") // Normal video
+}
@@ -0,0 +1,80 @@
+package notify
+
+import (
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"termidoro/timer"
+)
+
+func TestTerminalNotify(t *testing.T) {
+	var out strings.Builder
+	SetTerminal(&out)
+	defer SetTerminal(os.Stdout)
+
+	session := timer.Session{Type: timer.WORK, Duration: 10 * time.Minute}
+	done := Event{Type: SessionCompleted, Session: session, Title: "Done; really", Message: "Rest\a now"}
+	tests := []struct {
+		name string
+		t    Terminal
+		ev   Event
+		want string
+	}{
+		{"osc9", Terminal{Protocol: ProtocolOSC9}, done, "\033]9;Done; really: Rest  now\a\a"},
+		{"osc777", Terminal{Protocol: ProtocolOSC777}, done, "\033]777;notify;Done  really;Rest  now\a\a"},
+		{"bell", Terminal{Protocol: ProtocolBell}, done, "\a"},
+		{"muted", Terminal{Protocol: ProtocolOSC9}, Event{Type: SessionCompleted, Session: session, Title: "Done", Message: "Rest", Muted: true}, "\033]9;Done: Rest\a"},
+		{"tmux", Terminal{Protocol: ProtocolOSC9, Tmux: true}, done, "\033Ptmux;\033\033]9;Done; really: Rest  now\a\033\\\a"},
+		{"progress", Terminal{Progress: true}, Event{Type: SessionTick, Session: session, Remaining: 4 * time.Minute}, "\033]9;4;1;60\a"},
+		{"no progress", Terminal{}, Event{Type: SessionTick, Session: session, Remaining: 4 * time.Minute}, ""},
+		{"clear", Terminal{Progress: true}, Event{Type: SessionCancelled, Session: session}, "\033]9;4;0\a"},
+		{"voided", Terminal{Progress: true}, Event{Type: SessionVoided, Session: session}, "\033]9;4;0\a"},
+		{"started", Terminal{Progress: true}, Event{Type: SessionStarted, Session: session}, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out.Reset()
+			if err := tt.t.Notify(tt.ev); err != nil {
+				t.Fatal(err)
+			}
+			if out.String() != tt.want {
+				t.Errorf("Notify wrote %q, want %q", out.String(), tt.want)
+			}
+		})
+	}
+}
+
+func TestDetectTerminal(t *testing.T) {
+	env := func(vars ...string) func(string) string {
+		return func(name string) string {
+			for i := 0; i < len(vars); i += 2 {
+				if vars[i] == name {
+					return vars[i+1]
+				}
+			}
+			return ""
+		}
+	}
+
+	if got := DetectTerminal(env("TERM", "xterm-256color")); got.Protocol != ProtocolOSC9 || got.Tmux || got.Progress {
+		t.Errorf("Unexpected terminal: %+v", got)
+	}
+	if got := DetectTerminal(env("VTE_VERSION", "7600", "TMUX", "/tmp/tmux-0/default,1,0")); got.Protocol != ProtocolOSC777 || !got.Tmux {
+		t.Errorf("Expected OSC 777 through tmux, got %+v", got)
+	}
+	if got := DetectTerminal(env("TERM_PROGRAM", "ghostty")); !got.Progress {
+		t.Error("Expected progress in Ghostty")
+	}
+
+	if RemoteSession(env("TERM", "xterm")) {
+		t.Error("Expected a local session")
+	}
+	if !RemoteSession(env("SSH_CONNECTION", "10.0.0.2 50000 10.0.0.1 22")) {
+		t.Error("Expected SSH to count as remote")
+	}
+	if RemoteSession(env("TMUX", "x")) {
+		t.Error("Expected a local tmux not to count as remote")
+	}
+}
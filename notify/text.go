@@ -0,0 +1,208 @@
+package notify
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"text/template"
+	"time"
+
+	"termidoro/timer"
+)
+
+// Text events whose notification title and body can be templated.
+const (
+	TextWorkComplete      = "work_complete"
+	TextBreakComplete     = "break_complete"
+	TextLongBreakComplete = "long_break_complete"
+	TextWarning           = "warning"
+	TextMilestone         = "milestone"
+)
+
+var TextEvents = []string{TextWorkComplete, TextBreakComplete, TextLongBreakComplete, TextWarning, TextMilestone}
+
+// Text holds the title and body templates for one event. Either may be
+// empty to keep the default.
+type Text struct {
+	Title string
+	Body  string
+}
+
+// TextData is what the text templates are executed against.
+type TextData struct {
+	// Name is the session name and Type its kind: WORK, BREAK or LONG BREAK.
+	Name    string
+	Type    string
+	Cycle   int
+	Session int
+	// CompletedToday counts today's completed work sessions, including
+	// this one.
+	CompletedToday int
+	// Next and NextDuration describe the phase that follows, e.g. BREAK
+	// and 5m.
+	Next         string
+	NextDuration string
+	// Suggestion is something to do during the coming break.
+	Suggestion string
+	// Alert is the warning or milestone, e.g. "2 minutes left".
+	Alert     string
+	Remaining string
+}
+
+var (
+	breakSuggestions = []string{
+		"Stand up and stretch.",
+		"Drink a glass of water.",
+		"Look at something far away for 20 seconds.",
+		"Take a few deep breaths.",
+		"Roll your shoulders and loosen your neck.",
+	}
+	longBreakSuggestions = []string{
+		"Go for a short walk.",
+		"Have a snack and some water.",
+		"Step outside for some fresh air.",
+	}
+)
+
+// suggestion picks a break activity, rotating with the cycle.
+func suggestion(next timer.SessionType, cycle int) string {
+	list := breakSuggestions
+	switch next {
+	case timer.LONG_BREAK:
+		list = longBreakSuggestions
+	case timer.WORK:
+		return ""
+	}
+	return list[max(cycle-1, 0)%len(list)]
+}
+
+// shortDuration formats d as e.g. 5m, 1h30m or 45s.
+func shortDuration(d time.Duration) string {
+	d = d.Round(time.Second)
+	s := d.String()
+	if strings.HasSuffix(s, "m0s") {
+		s = strings.TrimSuffix(s, "0s")
+	}
+	if strings.HasSuffix(s, "h0m") {
+		s = strings.TrimSuffix(s, "0m")
+	}
+	return s
+}
+
+func newTextData(ev Event) TextData {
+	data := TextData{
+		Name:           ev.Session.Name,
+		Type:           ev.Session.Type.String(),
+		Cycle:          ev.Cycle,
+		Session:        ev.Number,
+		CompletedToday: ev.CompletedToday,
+		Alert:          ev.Alert,
+		Remaining:      shortDuration(ev.Remaining),
+	}
+	if ev.NextDuration > 0 {
+		data.Next = ev.Next.String()
+		data.NextDuration = shortDuration(ev.NextDuration)
+		data.Suggestion = suggestion(ev.Next, ev.Cycle)
+	}
+	return data
+}
+
+// textEvent returns the text event for ev, or "" if its text cannot be
+// templated.
+func textEvent(ev Event) string {
+	switch ev.Type {
+	case SessionWarning:
+		return TextWarning
+	case SessionMilestone:
+		return TextMilestone
+	case SessionCompleted:
+		switch ev.Session.Type {
+		case timer.BREAK:
+			return TextBreakComplete
+		case timer.LONG_BREAK:
+			return TextLongBreakComplete
+		}
+		return TextWorkComplete
+	}
+	return ""
+}
+
+// Texts holds parsed title and body templates per text event.
+type Texts struct {
+	titles map[string]*template.Template
+	bodies map[string]*template.Template
+}
+
+// NewTexts parses the templates and checks them against sample data.
+func NewTexts(texts map[string]Text) (*Texts, error) {
+	t := &Texts{
+		titles: map[string]*template.Template{},
+		bodies: map[string]*template.Template{},
+	}
+	for event, text := range texts {
+		if !isTextEvent(event) {
+			return nil, fmt.Errorf("unknown notification event %q (expected one of %s)", event, strings.Join(TextEvents, ", "))
+		}
+		for _, part := range []struct {
+			name   string
+			source string
+			dest   map[string]*template.Template
+		}{
+			{"title", text.Title, t.titles},
+			{"body", text.Body, t.bodies},
+		} {
+			if part.source == "" {
+				continue
+			}
+			tmpl, err := template.New(event + " " + part.name).Parse(part.source)
+			if err != nil {
+				return nil, err
+			}
+			if err := tmpl.Execute(io.Discard, TextData{}); err != nil {
+				return nil, err
+			}
+			part.dest[event] = tmpl
+		}
+	}
+	return t, nil
+}
+
+func isTextEvent(event string) bool {
+	for _, e := range TextEvents {
+		if e == event {
+			return true
+		}
+	}
+	return false
+}
+
+// render executes the templates for ev. A long break without its own
+// templates uses the break ones. Empty results keep the defaults.
+func (t *Texts) render(ev Event) (title, body string, err error) {
+	event := textEvent(ev)
+	if event == "" {
+		return "", "", nil
+	}
+	data := newTextData(ev)
+	execute := func(templates map[string]*template.Template) (string, error) {
+		tmpl, ok := templates[event]
+		if !ok && event == TextLongBreakComplete {
+			tmpl, ok = templates[TextBreakComplete]
+		}
+		if !ok {
+			return "", nil
+		}
+		var b strings.Builder
+		if err := tmpl.Execute(&b, data); err != nil {
+			return "", err
+		}
+		return strings.TrimSpace(b.String()), nil
+	}
+	if title, err = execute(t.titles); err != nil {
+		return "", "", err
+	}
+	if body, err = execute(t.bodies); err != nil {
+		return "", "", err
+	}
+	return title, body, nil
+}
@@ -0,0 +1,79 @@
+package notify
+
+import (
+	"testing"
+	"time"
+
+	"termidoro/timer"
+)
+
+func TestTexts(t *testing.T) {
+	texts, err := NewTexts(map[string]Text{
+		TextWorkComplete: {
+			Title: "{{.Name}} #{{.CompletedToday}}",
+			Body:  "{{.NextDuration}} {{.Next}}. {{.Suggestion}}",
+		},
+		TextBreakComplete: {Body: "Back to it, cycle {{.Cycle}}"},
+		TextWarning:       {Title: "{{.Alert}} of {{.Name}}"},
+	})
+	if err != nil {
+		t.Fatalf("NewTexts: %v", err)
+	}
+
+	work := Event{
+		Type:           SessionCompleted,
+		Session:        timer.Session{Type: timer.WORK, Name: "Deep Work"},
+		Cycle:          2,
+		CompletedToday: 5,
+		Next:           timer.BREAK,
+		NextDuration:   5 * time.Minute,
+	}
+	title, body, err := texts.render(work)
+	if err != nil {
+		t.Fatalf("render: %v", err)
+	}
+	if title != "Deep Work #5" || body != "5m BREAK. "+breakSuggestions[1] {
+		t.Errorf("Unexpected work text: %q, %q", title, body)
+	}
+
+	long := Event{Type: SessionCompleted, Session: timer.Session{Type: timer.LONG_BREAK}, Cycle: 4}
+	if title, body, _ := texts.render(long); title != "" || body != "Back to it, cycle 4" {
+		t.Errorf("Expected long breaks to use the break text, got %q, %q", title, body)
+	}
+
+	warning := Event{Type: SessionWarning, Session: timer.Session{Name: "Writing"}, Alert: "2 minutes left"}
+	if title, _, _ := texts.render(warning); title != "2 minutes left of Writing" {
+		t.Errorf("Unexpected warning title: %q", title)
+	}
+
+	if title, body, _ := texts.render(Event{Type: SessionStarted}); title != "" || body != "" {
+		t.Error("Expected no text for session starts")
+	}
+}
+
+func TestNewTextsErrors(t *testing.T) {
+	for _, texts := range []map[string]Text{
+		{"lunch": {Title: "Eat"}},
+		{TextWorkComplete: {Title: "{{.Name"}},
+		{TextWorkComplete: {Body: "{{.Nmae}}"}},
+	} {
+		if _, err := NewTexts(texts); err == nil {
+			t.Errorf("Expected error for %v", texts)
+		}
+	}
+}
+
+func TestShortDuration(t *testing.T) {
+	tests := map[time.Duration]string{
+		5 * time.Minute:              "5m",
+		90 * time.Minute:             "1h30m",
+		45 * time.Second:             "45s",
+		2 * time.Hour:                "2h",
+		time.Minute + 30*time.Second: "1m30s",
+	}
+	for d, want := range tests {
+		if got := shortDuration(d); got != want {
+			t.Errorf("shortDuration(%v) = %q, want %q", d, got, want)
+		}
+	}
+}
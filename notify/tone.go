@@ -0,0 +1,85 @@
+package notify
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math"
+	"time"
+)
+
+const (
+	sampleRate = 44100
+	// fadeTime ramps each tone in and out so it starts and stops without
+	// a click.
+	fadeTime = 10 * time.Millisecond
+)
+
+// tone is a sine wave at frequency Hz.
+type tone struct {
+	frequency float64
+	duration  time.Duration
+}
+
+var (
+	// workChime rises (A5, D6) to signal the end of a work session.
+	workChime = []tone{{880, 150 * time.Millisecond}, {1175, 250 * time.Millisecond}}
+	// breakChime falls (E5, A4) to signal the end of a break.
+	breakChime = []tone{{660, 150 * time.Millisecond}, {440, 250 * time.Millisecond}}
+	// alertTone is a double beep for warnings and milestones.
+	alertTone = []tone{{1320, 100 * time.Millisecond}, {0, 60 * time.Millisecond}, {1320, 100 * time.Millisecond}}
+	// tickClick is a short, quiet click for ticking sessions.
+	tickClick = []tone{{2000, 20 * time.Millisecond}}
+	// countdownBeep marks each of the last seconds of a session.
+	countdownBeep = []tone{{1000, 100 * time.Millisecond}}
+)
+
+// synthesize renders tones back to back as 16-bit mono PCM at the given
+// volume (0 to 1).
+func synthesize(tones []tone, volume float64) []int16 {
+	volume = math.Max(0, math.Min(1, volume))
+	var samples []int16
+	for _, t := range tones {
+		n := int(t.duration.Seconds() * sampleRate)
+		fade := min(int(fadeTime.Seconds()*sampleRate), n/2)
+		for i := 0; i < n; i++ {
+			amplitude := volume
+			if i < fade {
+				amplitude *= float64(i) / float64(fade)
+			} else if n-i < fade {
+				amplitude *= float64(n-i) / float64(fade)
+			}
+			v := amplitude * math.Sin(2*math.Pi*t.frequency*float64(i)/sampleRate)
+			samples = append(samples, int16(v*math.MaxInt16))
+		}
+	}
+	return samples
+}
+
+// encodeWAV wraps 16-bit mono PCM samples in a WAV container.
+func encodeWAV(samples []int16) []byte {
+	const (
+		channels      = 1
+		bitsPerSample = 16
+		blockAlign    = channels * bitsPerSample / 8
+	)
+	dataSize := uint32(len(samples) * blockAlign)
+
+	var buf bytes.Buffer
+	buf.WriteString("RIFF")
+	binary.Write(&buf, binary.LittleEndian, 36+dataSize)
+	buf.WriteString("WAVE")
+
+	buf.WriteString("fmt ")
+	binary.Write(&buf, binary.LittleEndian, uint32(16)) // fmt chunk size
+	binary.Write(&buf, binary.LittleEndian, uint16(1))  // PCM
+	binary.Write(&buf, binary.LittleEndian, uint16(channels))
+	binary.Write(&buf, binary.LittleEndian, uint32(sampleRate))
+	binary.Write(&buf, binary.LittleEndian, uint32(sampleRate*blockAlign))
+	binary.Write(&buf, binary.LittleEndian, uint16(blockAlign))
+	binary.Write(&buf, binary.LittleEndian, uint16(bitsPerSample))
+
+	buf.WriteString("data")
+	binary.Write(&buf, binary.LittleEndian, dataSize)
+	binary.Write(&buf, binary.LittleEndian, samples)
+	return buf.Bytes()
+}
@@ -0,0 +1,96 @@
+package notify
+
+import (
+	"encoding/binary"
+	"math"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"time"
+)
+
+func TestSynthesize(t *testing.T) {
+	samples := synthesize([]tone{{440, 500 * time.Millisecond}}, 0.5)
+	if want := sampleRate / 2; len(samples) != want {
+		t.Fatalf("Expected %d samples, got %d", want, len(samples))
+	}
+	if samples[0] != 0 {
+		t.Errorf("Expected the tone to fade in from silence, got %d", samples[0])
+	}
+
+	peak := 0
+	crossings := 0
+	for i, s := range samples {
+		peak = max(peak, int(math.Abs(float64(s))))
+		if i > 0 && (samples[i-1] < 0) != (s < 0) {
+			crossings++
+		}
+	}
+	if limit := math.MaxInt16 / 2; peak > limit || peak < limit*9/10 {
+		t.Errorf("Expected peak near %d, got %d", limit, peak)
+	}
+	// A 440 Hz sine crosses zero twice per period: ~440 times in 0.5s.
+	if crossings < 430 || crossings > 450 {
+		t.Errorf("Expected about 440 zero crossings, got %d", crossings)
+	}
+
+	if got := synthesize(workChime, 0); len(got) == 0 || got[len(got)/2] != 0 {
+		t.Error("Expected silence at zero volume")
+	}
+}
+
+func TestEncodeWAV(t *testing.T) {
+	samples := []int16{0, 1000, -1000, 32767}
+	data := encodeWAV(samples)
+
+	if len(data) != 44+len(samples)*2 {
+		t.Fatalf("Expected %d bytes, got %d", 44+len(samples)*2, len(data))
+	}
+	if string(data[0:4]) != "RIFF" || string(data[8:12]) != "WAVE" || string(data[36:40]) != "data" {
+		t.Errorf("Unexpected WAV header: %q", data[:44])
+	}
+	if rate := binary.LittleEndian.Uint32(data[24:28]); rate != sampleRate {
+		t.Errorf("Expected sample rate %d, got %d", sampleRate, rate)
+	}
+	if size := binary.LittleEndian.Uint32(data[40:44]); size != 8 {
+		t.Errorf("Expected data size 8, got %d", size)
+	}
+	if last := int16(binary.LittleEndian.Uint16(data[50:52])); last != 32767 {
+		t.Errorf("Expected last sample 32767, got %d", last)
+	}
+}
+
+func TestPlayerFallback(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("player scripts need a POSIX shell")
+	}
+	dir := t.TempDir()
+	played := filepath.Join(dir, "played")
+	scripts := map[string]string{
+		"paplay": "#!/bin/sh\nexit 1\n",
+		"aplay":  "#!/bin/sh\necho \"$@\" > " + played + "\n",
+	}
+	for name, script := range scripts {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(script), 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	t.Setenv("PATH", dir)
+	workingPlayer = ""
+	defer func() { workingPlayer = "" }()
+
+	if err := playWAV(encodeWAV(synthesize(breakChime, 0.5)), nil); err != nil {
+		t.Fatalf("playWAV: %v", err)
+	}
+	if _, err := os.Stat(played); err != nil {
+		t.Error("Expected aplay to play the chime after paplay failed")
+	}
+	if workingPlayer != "aplay" {
+		t.Errorf("Expected aplay to be remembered, got %q", workingPlayer)
+	}
+
+	if err := playWAV(nil, []string{"pw-play", "ffplay"}); err == nil {
+		t.Error("Expected an error when no player is installed")
+	}
+}
@@ -0,0 +1,193 @@
+package notify
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"text/template"
+	"time"
+
+	"termidoro/timer"
+)
+
+const (
+	defaultWebhookTimeout = 5 * time.Second
+	defaultWebhookRetries = 3
+)
+
+// webhookBackoff is the delay before the first retry; it doubles after
+// every failed attempt.
+var webhookBackoff = time.Second
+
+// WebhookEvent is the payload posted for a session event. Without a body
+// template it is sent as JSON; with one, it is the template's data.
+type WebhookEvent struct {
+	Event   string    `json:"event"`
+	Time    time.Time `json:"time"`
+	Type    string    `json:"type"`
+	Name    string    `json:"name,omitempty"`
+	Cycle   int       `json:"cycle"`
+	Session int       `json:"session"`
+	Planned int64     `json:"planned_secs"`
+	Elapsed int64     `json:"elapsed_secs"`
+}
+
+// Webhook posts session events to one HTTP endpoint.
+type Webhook struct {
+	URL     string
+	Headers map[string]string
+	Timeout time.Duration
+	Retries int
+	// Events limits which events are sent; empty means all of them.
+	Events []EventType
+	body   *template.Template
+	client *http.Client
+}
+
+var webhookFuncs = template.FuncMap{
+	// json encodes a value, e.g. {{json .Name}} for a quoted string.
+	"json": func(v any) (string, error) {
+		data, err := json.Marshal(v)
+		return string(data), err
+	},
+}
+
+// NewWebhook validates the settings of a webhook. body is an optional
+// text/template for the request body; zero timeout and negative retries
+// fall back to the defaults.
+func NewWebhook(url, body string, headers map[string]string, timeout time.Duration, retries int, events []string) (*Webhook, error) {
+	if !strings.HasPrefix(url, "http://") && !strings.HasPrefix(url, "https://") {
+		return nil, fmt.Errorf("webhook url %q must start with http:// or https://", url)
+	}
+	var types []EventType
+	for _, e := range events {
+		switch t := EventType(e); t {
+		case SessionStarted, SessionCompleted, SessionSkipped, SessionCancelled, SessionVoided:
+			types = append(types, t)
+		default:
+			return nil, fmt.Errorf("unknown webhook event %q", e)
+		}
+	}
+	if timeout <= 0 {
+		timeout = defaultWebhookTimeout
+	}
+	if retries < 0 {
+		retries = defaultWebhookRetries
+	}
+
+	w := &Webhook{
+		URL:     url,
+		Headers: headers,
+		Timeout: timeout,
+		Retries: retries,
+		Events:  types,
+		client:  &http.Client{Timeout: timeout},
+	}
+	if body != "" {
+		tmpl, err := template.New(url).Funcs(webhookFuncs).Parse(body)
+		if err != nil {
+			return nil, fmt.Errorf("webhook body for %s: %w", url, err)
+		}
+		w.body = tmpl
+	}
+	return w, nil
+}
+
+func (w *Webhook) wants(event EventType) bool {
+	if len(w.Events) == 0 {
+		return true
+	}
+	for _, e := range w.Events {
+		if e == event {
+			return true
+		}
+	}
+	return false
+}
+
+func (w *Webhook) payload(ev WebhookEvent) ([]byte, error) {
+	if w.body == nil {
+		return json.Marshal(ev)
+	}
+	var buf bytes.Buffer
+	if err := w.body.Execute(&buf, ev); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// deliver posts ev, retrying with exponential backoff on network errors and
+// non-2xx responses.
+func (w *Webhook) deliver(ev WebhookEvent) error {
+	body, err := w.payload(ev)
+	if err != nil {
+		return err
+	}
+
+	backoff := webhookBackoff
+	for attempt := 0; ; attempt++ {
+		err = w.post(body)
+		if err == nil || attempt >= w.Retries {
+			return err
+		}
+		time.Sleep(backoff)
+		backoff *= 2
+	}
+}
+
+func (w *Webhook) post(body []byte) error {
+	req, err := http.NewRequest(http.MethodPost, w.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "termidoro")
+	for k, v := range w.Headers {
+		req.Header.Set(k, v)
+	}
+
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("unexpected status %s", resp.Status)
+	}
+	return nil
+}
+
+// Webhooks posts events to every configured endpoint in the background, so
+// a slow or unreachable endpoint never holds up the countdown.
+type Webhooks []*Webhook
+
+func (Webhooks) Name() string { return "webhook" }
+
+func (ws Webhooks) Notify(ev Event) error {
+	switch ev.Type {
+	case SessionStarted, SessionCompleted, SessionSkipped, SessionCancelled, SessionVoided:
+	default:
+		return nil
+	}
+	payload := WebhookEvent{
+		Event:   string(ev.Type),
+		Time:    ev.Time,
+		Type:    timer.TypeName(ev.Session.Type),
+		Name:    ev.Session.Name,
+		Cycle:   ev.Cycle,
+		Session: ev.Number,
+		Planned: int64(ev.Session.Duration / time.Second),
+		Elapsed: int64(ev.Session.Elapsed / time.Second),
+	}
+	for _, w := range ws {
+		if !w.wants(ev.Type) {
+			continue
+		}
+		Go("webhook "+w.URL, func() error {
+			return w.deliver(payload)
+		})
+	}
+	return nil
+}
@@ -0,0 +1,114 @@
+package notify
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"termidoro/timer"
+)
+
+func TestWebhookRetriesAndTemplate(t *testing.T) {
+	webhookBackoff = time.Millisecond
+	defer func() { webhookBackoff = time.Second }()
+
+	var attempts atomic.Int32
+	bodies := make(chan string, 5)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer token" {
+			t.Errorf("Missing header, got %q", r.Header.Get("Authorization"))
+		}
+		if attempts.Add(1) < 3 {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		data, _ := io.ReadAll(r.Body)
+		bodies <- string(data)
+	}))
+	defer server.Close()
+
+	w, err := NewWebhook(server.URL, `{"text": {{json .Name}}, "cycle": {{.Cycle}}}`,
+		map[string]string{"Authorization": "Bearer token"}, time.Second, 2, []string{"session_completed"})
+	if err != nil {
+		t.Fatalf("NewWebhook: %v", err)
+	}
+	registry := NewRegistry(Webhooks{w})
+	registry.Notify(Event{Type: SessionStarted, Session: timer.Session{Name: "ignored"}})
+	registry.Notify(Event{Type: SessionCompleted, Session: timer.Session{Name: `Deep "Work"`}, Cycle: 2})
+	Wait(5 * time.Second)
+
+	select {
+	case body := <-bodies:
+		if want := `{"text": "Deep \"Work\"", "cycle": 2}`; body != want {
+			t.Errorf("Expected body %s, got %s", want, body)
+		}
+	default:
+		t.Fatal("Expected a delivered webhook")
+	}
+	if n := attempts.Load(); n != 3 {
+		t.Errorf("Expected 3 attempts, got %d", n)
+	}
+}
+
+func TestWebhookGivesUp(t *testing.T) {
+	webhookBackoff = time.Millisecond
+	defer func() { webhookBackoff = time.Second }()
+
+	var attempts atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts.Add(1)
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	w, err := NewWebhook(server.URL, "", nil, time.Second, 1, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := w.deliver(WebhookEvent{Event: string(SessionCancelled)}); err == nil {
+		t.Error("Expected delivery to fail")
+	}
+	if n := attempts.Load(); n != 2 {
+		t.Errorf("Expected 2 attempts, got %d", n)
+	}
+}
+
+func TestNewWebhookValidation(t *testing.T) {
+	if _, err := NewWebhook("ftp://example.com", "", nil, 0, 0, nil); err == nil {
+		t.Error("Expected error for non-HTTP url")
+	}
+	if _, err := NewWebhook("https://example.com", "{{.Nope", nil, 0, 0, nil); err == nil {
+		t.Error("Expected error for invalid template")
+	}
+	if _, err := NewWebhook("https://example.com", "", nil, 0, 0, []string{"lunch"}); err == nil {
+		t.Error("Expected error for unknown event")
+	}
+}
+
+func TestWebhookVoided(t *testing.T) {
+	bodies := make(chan string, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, _ := io.ReadAll(r.Body)
+		bodies <- string(data)
+	}))
+	defer server.Close()
+
+	w, err := NewWebhook(server.URL, "{{.Event}} {{.Type}}", nil, time.Second, 0, []string{"session_voided"})
+	if err != nil {
+		t.Fatalf("NewWebhook: %v", err)
+	}
+	NewRegistry(Webhooks{w}).Notify(Event{Type: SessionVoided, Session: timer.Session{Type: timer.WORK, Voided: true}})
+	Wait(5 * time.Second)
+
+	select {
+	case body := <-bodies:
+		if body != "session_voided work" {
+			t.Errorf("Unexpected body %q", body)
+		}
+	default:
+		t.Fatal("Expected the voided session to be posted")
+	}
+}
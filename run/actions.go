@@ -0,0 +1,106 @@
+package run
+
+import (
+	"time"
+
+	"termidoro/control"
+	"termidoro/timer"
+	"termidoro/ui"
+)
+
+// action is something the user asked the running session to do, either
+// from the keyboard or through the control socket.
+type action int
+
+const (
+	actionNone action = iota
+	actionToggle
+	actionPause
+	actionResume
+	actionSkip
+	actionExtend
+	actionRestart
+	actionCancel
+	actionInterrupt
+	actionInterruptExternal
+)
+
+// keyAction maps a key pressed during a session to an action and, for
+// extensions, the amount of time to add.
+func keyAction(key byte) (action, time.Duration) {
+	switch key {
+	case ui.KeyCtrlC:
+		return actionCancel, 0
+	case 'p', 'P', ' ':
+		return actionToggle, 0
+	case 's', 'S':
+		return actionSkip, 0
+	case '+', '=':
+		return actionExtend, adjustStep
+	case '-', '_':
+		return actionExtend, -adjustStep
+	case 'r', 'R':
+		return actionRestart, 0
+	case '\'', 'i', 'I':
+		return actionInterrupt, 0
+	case 'e', 'E':
+		return actionInterruptExternal, 0
+	}
+	return actionNone, 0
+}
+
+func commandAction(cmd control.Command) (action, time.Duration) {
+	switch cmd.Name {
+	case control.CmdPause:
+		return actionPause, 0
+	case control.CmdResume:
+		return actionResume, 0
+	case control.CmdToggle:
+		return actionToggle, 0
+	case control.CmdSkip:
+		return actionSkip, 0
+	case control.CmdExtend:
+		return actionExtend, cmd.Duration
+	case control.CmdStop:
+		return actionCancel, 0
+	}
+	return actionNone, 0
+}
+
+// controller is the control socket server, or nil when it could not be
+// started.
+var controller *control.Server
+
+func controlCommands() <-chan control.Command {
+	if controller == nil {
+		return nil
+	}
+	return controller.Commands()
+}
+
+func publishStatus(engine *timer.Engine, index int, elapsed, planned time.Duration) {
+	if controller == nil {
+		return
+	}
+	s := engine.Sessions[index]
+	state := control.StateRunning
+	if s.IsPaused() {
+		state = control.StatePaused
+	}
+	controller.SetStatus(control.Status{
+		State:     state,
+		Session:   index + 1,
+		Cycle:     s.Cycle,
+		Type:      timer.TypeName(s.Type),
+		Name:      s.Name,
+		Planned:   int64(planned / time.Second),
+		Elapsed:   int64(elapsed / time.Second),
+		Remaining: int64((planned - elapsed) / time.Second),
+	})
+}
+
+func publishWaiting() {
+	if controller != nil {
+		controller.SetState(control.StateWaiting)
+	}
+}
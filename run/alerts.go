@@ -0,0 +1,95 @@
+package run
+
+import (
+	"fmt"
+	"time"
+
+	"termidoro/config"
+	"termidoro/notify"
+	"termidoro/timer"
+	"termidoro/ui"
+)
+
+// alerts holds the warnings and milestones configured for the run.
+var alerts config.Alerts
+
+// alert is a warning or milestone due in a session.
+type alert struct {
+	kind notify.EventType
+	text string
+}
+
+// dueAlerts returns the alerts that fall in the second of the session
+// ending at elapsed. Alerts are placed relative to the current planned
+// duration, so they fire again after a restart or an extension.
+func dueAlerts(a config.Alerts, sessionType timer.SessionType, elapsed, duration time.Duration) []alert {
+	if sessionType.IsBreak() && !a.Breaks {
+		return nil
+	}
+	due := func(at time.Duration) bool {
+		return at > 0 && at < duration && at > elapsed-time.Second && at <= elapsed
+	}
+
+	var fired []alert
+	for _, percent := range a.Milestones {
+		if due(duration * time.Duration(percent) / 100) {
+			fired = append(fired, alert{notify.SessionMilestone, milestoneText(percent)})
+		}
+	}
+	for _, before := range a.BeforeEnd {
+		if due(duration - before) {
+			fired = append(fired, alert{notify.SessionWarning, remainingText(before)})
+		}
+	}
+	return fired
+}
+
+func milestoneText(percent int) string {
+	if percent == 50 {
+		return "Halfway there"
+	}
+	return fmt.Sprintf("%d%% done", percent)
+}
+
+func remainingText(d time.Duration) string {
+	minutes := int(d / time.Minute)
+	secs := int(d/time.Second) % 60
+	switch {
+	case minutes == 0:
+		return plural(secs, "second") + " left"
+	case secs == 0:
+		return plural(minutes, "minute") + " left"
+	default:
+		return fmt.Sprintf("%dm %ds left", minutes, secs)
+	}
+}
+
+func plural(n int, unit string) string {
+	if n == 1 {
+		return fmt.Sprintf("1 %s", unit)
+	}
+	return fmt.Sprintf("%d %ss", n, unit)
+}
+
+// raiseAlerts shows the alerts due at elapsed and sends them through the
+// configured notifiers.
+func raiseAlerts(progress *ui.Renderer, engine *timer.Engine, index int, elapsed, duration time.Duration) {
+	s := engine.Sessions[index]
+	for _, a := range dueAlerts(alerts, s.Type, elapsed, duration) {
+		progress.ShowAlert(a.text)
+		events.alert(string(a.kind), a.text, engine, index, elapsed, duration)
+		ev := notify.Event{
+			Type:           a.kind,
+			Session:        s,
+			Number:         index + 1,
+			Cycle:          s.Cycle,
+			Remaining:      duration - elapsed,
+			Alert:          a.text,
+			CompletedToday: completedToday,
+		}
+		if runConfig != nil {
+			ev.Next, ev.NextDuration = nextPhase(runConfig, s)
+		}
+		notifiers.NotifyVia(ev, alerts.Via)
+	}
+}
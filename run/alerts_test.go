@@ -0,0 +1,60 @@
+package run
+
+import (
+	"testing"
+	"time"
+
+	"termidoro/config"
+	"termidoro/notify"
+	"termidoro/timer"
+)
+
+func TestDueAlerts(t *testing.T) {
+	a := config.Alerts{
+		BeforeEnd:  []time.Duration{2 * time.Minute, 30 * time.Minute},
+		Milestones: []int{50},
+	}
+	duration := 25 * time.Minute
+
+	var fired []alert
+	var at []time.Duration
+	for elapsed := time.Second; elapsed <= duration; elapsed += time.Second {
+		for _, al := range dueAlerts(a, timer.WORK, elapsed, duration) {
+			fired = append(fired, al)
+			at = append(at, elapsed)
+		}
+	}
+	if len(fired) != 2 {
+		t.Fatalf("Expected 2 alerts, got %+v", fired)
+	}
+	if fired[0].kind != notify.SessionMilestone || fired[0].text != "Halfway there" || at[0] != 12*time.Minute+30*time.Second {
+		t.Errorf("Unexpected milestone %+v at %v", fired[0], at[0])
+	}
+	if fired[1].kind != notify.SessionWarning || fired[1].text != "2 minutes left" || at[1] != 23*time.Minute {
+		t.Errorf("Unexpected warning %+v at %v", fired[1], at[1])
+	}
+
+	if got := dueAlerts(a, timer.BREAK, 23*time.Minute, duration); got != nil {
+		t.Errorf("Expected no alerts during breaks, got %+v", got)
+	}
+	a.Breaks = true
+	if got := dueAlerts(a, timer.BREAK, 23*time.Minute, duration); len(got) != 1 {
+		t.Errorf("Expected the warning during breaks, got %+v", got)
+	}
+}
+
+func TestRemainingText(t *testing.T) {
+	tests := map[time.Duration]string{
+		time.Minute:                "1 minute left",
+		5 * time.Minute:            "5 minutes left",
+		30 * time.Second:           "30 seconds left",
+		90 * time.Second:           "1m 30s left",
+		time.Second:                "1 second left",
+		time.Hour + 15*time.Minute: "75 minutes left",
+	}
+	for d, want := range tests {
+		if got := remainingText(d); got != want {
+			t.Errorf("remainingText(%v) = %q, want %q", d, got, want)
+		}
+	}
+}
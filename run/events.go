@@ -0,0 +1,186 @@
+package run
+
+import (
+	"encoding/json"
+	"io"
+	"time"
+
+	"termidoro/timer"
+)
+
+// Event names written in JSON output mode.
+const (
+	eventCycleStarted     = "cycle_started"
+	eventSessionStarted   = "session_started"
+	eventTick             = "tick"
+	eventSessionPaused    = "session_paused"
+	eventSessionResumed   = "session_resumed"
+	eventSessionCompleted = "session_completed"
+	eventSessionCancelled = "session_cancelled"
+	eventSessionSkipped   = "session_skipped"
+	eventSessionVoided    = "session_voided"
+	eventSessionWarning   = "session_warning"
+	eventSessionMilestone = "session_milestone"
+	eventRecap            = "recap"
+)
+
+// event is one line of the JSON stream. Durations are whole seconds.
+type event struct {
+	Event     string         `json:"event"`
+	Time      time.Time      `json:"time"`
+	Session   int            `json:"session,omitempty"`
+	Cycle     int            `json:"cycle,omitempty"`
+	Type      string         `json:"type,omitempty"`
+	Name      string         `json:"name,omitempty"`
+	Planned   *int64         `json:"planned_secs,omitempty"`
+	Elapsed   *int64         `json:"elapsed_secs,omitempty"`
+	Remaining *int64         `json:"remaining_secs,omitempty"`
+	Message   string         `json:"message,omitempty"`
+	Sessions  []recapSession `json:"sessions,omitempty"`
+	Focused   *int64         `json:"focused_secs,omitempty"`
+}
+
+type recapSession struct {
+	Type      string    `json:"type"`
+	Name      string    `json:"name,omitempty"`
+	Cycle     int       `json:"cycle"`
+	Planned   int64     `json:"planned_secs"`
+	Elapsed   int64     `json:"elapsed_secs"`
+	Paused    int64     `json:"paused_secs"`
+	StartTime time.Time `json:"start"`
+	EndTime   time.Time `json:"end"`
+	Completed bool      `json:"completed"`
+	Cancelled bool      `json:"cancelled"`
+	Skipped   bool      `json:"skipped"`
+	Voided    bool      `json:"voided"`
+	Note      string    `json:"note,omitempty"`
+	Intention string    `json:"intention,omitempty"`
+	Outcome   string    `json:"outcome,omitempty"`
+
+	Interruptions int `json:"interruptions"`
+}
+
+// emitter writes newline-delimited JSON events. A nil emitter discards
+// everything, which is what the terminal UI mode uses.
+type emitter struct {
+	enc          *json.Encoder
+	tickInterval int
+}
+
+func newEmitter(w io.Writer, tickInterval time.Duration) *emitter {
+	interval := int(tickInterval / time.Second)
+	if interval < 1 {
+		interval = 1
+	}
+	return &emitter{enc: json.NewEncoder(w), tickInterval: interval}
+}
+
+var events *emitter
+
+func seconds(d time.Duration) *int64 {
+	s := int64(d / time.Second)
+	return &s
+}
+
+func (e *emitter) emit(ev event) {
+	if e == nil {
+		return
+	}
+	ev.Time = time.Now()
+	e.enc.Encode(ev)
+}
+
+func (e *emitter) cycleStarted(cycle int) {
+	e.emit(event{Event: eventCycleStarted, Cycle: cycle})
+}
+
+// session emits a session-level event for the session at index.
+func (e *emitter) session(name string, engine *timer.Engine, index int) {
+	if e == nil {
+		return
+	}
+	s := engine.Sessions[index]
+	ev := event{
+		Event:   name,
+		Session: index + 1,
+		Cycle:   s.Cycle,
+		Type:    timer.TypeName(s.Type),
+		Name:    s.Name,
+		Planned: seconds(s.Duration),
+	}
+	if s.IsFinished() {
+		ev.Elapsed = seconds(s.Elapsed)
+	}
+	e.emit(ev)
+}
+
+// tick reports progress, throttled to the configured interval. The final
+// second is always reported.
+func (e *emitter) tick(engine *timer.Engine, index int, elapsed, planned time.Duration) {
+	if e == nil {
+		return
+	}
+	secs := int(elapsed / time.Second)
+	if secs%e.tickInterval != 0 && elapsed < planned {
+		return
+	}
+	s := engine.Sessions[index]
+	e.emit(event{
+		Event:     eventTick,
+		Session:   index + 1,
+		Cycle:     s.Cycle,
+		Type:      timer.TypeName(s.Type),
+		Name:      s.Name,
+		Planned:   seconds(planned),
+		Elapsed:   seconds(elapsed),
+		Remaining: seconds(planned - elapsed),
+	})
+}
+
+// alert reports a warning or milestone of the session at index.
+func (e *emitter) alert(name, message string, engine *timer.Engine, index int, elapsed, planned time.Duration) {
+	if e == nil {
+		return
+	}
+	s := engine.Sessions[index]
+	e.emit(event{
+		Event:     name,
+		Session:   index + 1,
+		Cycle:     s.Cycle,
+		Type:      timer.TypeName(s.Type),
+		Name:      s.Name,
+		Planned:   seconds(planned),
+		Elapsed:   seconds(elapsed),
+		Remaining: seconds(planned - elapsed),
+		Message:   message,
+	})
+}
+
+func (e *emitter) recap(engine *timer.Engine) {
+	if e == nil {
+		return
+	}
+	sessions := make([]recapSession, len(engine.Sessions))
+	for i, s := range engine.Sessions {
+		sessions[i] = recapSession{
+			Type:      timer.TypeName(s.Type),
+			Name:      s.Name,
+			Cycle:     s.Cycle,
+			Planned:   *seconds(s.Duration),
+			Elapsed:   *seconds(s.Elapsed),
+			Paused:    *seconds(s.Paused),
+			StartTime: s.StartTime,
+			EndTime:   s.EndTime,
+			Completed: s.Completed,
+			Cancelled: s.WasCancelled,
+			Skipped:   s.Skipped,
+			Voided:    s.Voided,
+			Note:      s.Note,
+			Intention: s.Intention,
+			Outcome:   s.Outcome,
+
+			Interruptions: len(s.Interruptions),
+		}
+	}
+	e.emit(event{Event: eventRecap, Sessions: sessions, Focused: seconds(engine.TotalTime)})
+}
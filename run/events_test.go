@@ -0,0 +1,47 @@
+package run
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"termidoro/timer"
+)
+
+func TestEmitterTickThrottle(t *testing.T) {
+	var buf bytes.Buffer
+	e := newEmitter(&buf, 5*time.Second)
+
+	engine := timer.NewEngine()
+	engine.AddSession(12*time.Second, timer.WORK)
+	for i := 1; i <= 12; i++ {
+		e.tick(engine, 0, time.Duration(i)*time.Second, 12*time.Second)
+	}
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	var elapsed []int64
+	for _, line := range lines {
+		var ev event
+		if err := json.Unmarshal([]byte(line), &ev); err != nil {
+			t.Fatalf("Invalid JSON %q: %v", line, err)
+		}
+		if ev.Event != eventTick {
+			t.Errorf("Expected tick event, got %s", ev.Event)
+		}
+		elapsed = append(elapsed, *ev.Elapsed)
+	}
+	if want := []int64{5, 10, 12}; len(elapsed) != len(want) || elapsed[0] != 5 || elapsed[1] != 10 || elapsed[2] != 12 {
+		t.Errorf("Expected ticks at %v, got %v", want, elapsed)
+	}
+}
+
+func TestNilEmitter(t *testing.T) {
+	var e *emitter
+	engine := timer.NewEngine()
+	engine.AddSession(time.Minute, timer.WORK)
+	e.session(eventSessionStarted, engine, 0)
+	e.tick(engine, 0, time.Second, time.Minute)
+	e.recap(engine)
+}
@@ -0,0 +1,67 @@
+package run
+
+import (
+	"fmt"
+	"strings"
+
+	"termidoro/timer"
+	"termidoro/ui"
+)
+
+// intentionQuestion asks what the next work session sets out to do.
+const intentionQuestion = "Intention for this pomodoro (Enter for none): "
+
+// intending reports whether the run asks for intentions and reviews them.
+func intending() bool {
+	return runConfig != nil && runConfig.Intentions && !runConfig.AutoYes
+}
+
+// askIntention asks for the intention of the next work session. It reports
+// false when the run was interrupted.
+func askIntention(r *ui.Renderer) (string, bool) {
+	if !intending() {
+		return "", true
+	}
+	return ask(r, intentionQuestion)
+}
+
+// askOutcome asks whether the session at index achieved its intention,
+// until the answer is yes, partly, no or empty to leave it unreviewed. It
+// reports false when the run was interrupted.
+func askOutcome(r *ui.Renderer, engine *timer.Engine, index int) bool {
+	intention := engine.Sessions[index].Intention
+	if !intending() || intention == "" {
+		return true
+	}
+	question := fmt.Sprintf("Did you achieve %q? [y]es/[p]artly/[n]o: ", intention)
+	for {
+		answer, answered := ask(r, question)
+		if !answered {
+			return false
+		}
+		outcome, ok := parseOutcome(answer)
+		if !ok {
+			continue
+		}
+		if outcome != "" {
+			engine.SetOutcome(index, outcome)
+		}
+		return true
+	}
+}
+
+// parseOutcome turns an answer to the outcome question into one of the
+// timer outcomes. An empty answer is valid and leaves the outcome unset.
+func parseOutcome(answer string) (string, bool) {
+	switch strings.ToLower(strings.TrimSpace(answer)) {
+	case "":
+		return "", true
+	case "y", "yes":
+		return timer.OutcomeYes, true
+	case "p", "partly":
+		return timer.OutcomePartly, true
+	case "n", "no":
+		return timer.OutcomeNo, true
+	}
+	return "", false
+}
@@ -0,0 +1,45 @@
+package run
+
+import (
+	"os"
+	"testing"
+
+	"termidoro/timer"
+)
+
+func TestParseOutcome(t *testing.T) {
+	tests := []struct {
+		answer string
+		want   string
+		ok     bool
+	}{
+		{"y", timer.OutcomeYes, true},
+		{" Partly ", timer.OutcomePartly, true},
+		{"NO", timer.OutcomeNo, true},
+		{"", "", true},
+		{"maybe", "", false},
+	}
+	for _, tt := range tests {
+		if got, ok := parseOutcome(tt.answer); got != tt.want || ok != tt.ok {
+			t.Errorf("parseOutcome(%q) = %q, %v, want %q, %v", tt.answer, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestReadAnswer(t *testing.T) {
+	keys := make(chan byte, 16)
+	for _, b := range []byte(" partly \r") {
+		keys <- b
+	}
+	if answer, ok := readAnswer(keys, nil); answer != "partly" || !ok {
+		t.Errorf("Expected the trimmed answer, got %q, %v", answer, ok)
+	}
+
+	stop := make(chan os.Signal, 1)
+	keys <- 'n'
+	stop <- os.Interrupt
+	// Whether or not the key is read first, the signal ends the line.
+	if answer, ok := readAnswer(keys, stop); answer != "" || ok {
+		t.Errorf("Expected an interrupted answer, got %q, %v", answer, ok)
+	}
+}
@@ -0,0 +1,78 @@
+package run
+
+import (
+	"fmt"
+	"unicode/utf8"
+
+	"termidoro/timer"
+	"termidoro/ui"
+)
+
+// notePrompt asks for the note of an interruption on the prompt line.
+const notePrompt = "Interruption note (Enter to save, Esc to skip): "
+
+// noteInput collects the one-line note typed after an interruption while
+// the session keeps running.
+type noteInput struct {
+	active bool
+	text   []byte
+}
+
+func (n *noteInput) start() {
+	n.active = true
+	n.text = n.text[:0]
+}
+
+// key adds a key press to the note. It reports whether the note is
+// finished: Enter keeps it and Escape throws it away.
+func (n *noteInput) key(b byte) bool {
+	switch b {
+	case ui.KeyEnter, '\n':
+		return true
+	case ui.KeyEscape:
+		n.text = n.text[:0]
+		return true
+	case ui.KeyBackspace, '\b':
+		if len(n.text) > 0 {
+			_, size := utf8.DecodeLastRune(n.text)
+			n.text = n.text[:len(n.text)-size]
+		}
+	default:
+		if b >= ' ' {
+			n.text = append(n.text, b)
+		}
+	}
+	return false
+}
+
+// finish ends the note and returns its text.
+func (n *noteInput) finish() string {
+	n.active = false
+	return string(n.text)
+}
+
+func (n *noteInput) String() string {
+	return string(n.text)
+}
+
+// voidLimit is the number of interruptions a work session may have before
+// it is voided, or 0 for no limit.
+func voidLimit() int {
+	if runConfig == nil {
+		return 0
+	}
+	return runConfig.VoidAfter
+}
+
+// lastVoided reports whether the latest session of the run was voided.
+func lastVoided(engine *timer.Engine) bool {
+	n := len(engine.Sessions)
+	return n > 0 && engine.Sessions[n-1].Voided
+}
+
+// promptRestart asks whether to start a new pomodoro in place of the one
+// that was just voided.
+func promptRestart(r *ui.Renderer, s timer.Session) bool {
+	r.DisplayMessage(fmt.Sprintf("Pomodoro voided after %d interruptions.", len(s.Interruptions)))
+	return awaitNext(r, "Start a new one? [Y/n]: ")
+}
@@ -0,0 +1,28 @@
+package run
+
+import "testing"
+
+func TestNoteInput(t *testing.T) {
+	var n noteInput
+	n.start()
+	for _, b := range []byte("cafe\x7f\xc3\xa9 call") {
+		if n.key(b) {
+			t.Fatalf("Expected the note to stay open after %q", b)
+		}
+	}
+	for range len(" call") + 1 {
+		n.key(0x7f)
+	}
+	if n.String() != "caf" {
+		t.Errorf("Expected backspace to remove whole characters, got %q", n.String())
+	}
+	if !n.key('\r') || n.finish() != "caf" || n.active {
+		t.Error("Expected Enter to finish the note")
+	}
+
+	n.start()
+	n.key('x')
+	if !n.key(27) || n.finish() != "" {
+		t.Error("Expected Escape to discard the note")
+	}
+}
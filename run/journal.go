@@ -0,0 +1,102 @@
+package run
+
+import (
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+
+	"termidoro/timer"
+	"termidoro/ui"
+)
+
+// journalQuestion asks for the journal entry of a work session.
+const journalQuestion = "What did you get done? "
+
+// journaling reports whether the run asks for journal entries. Nobody
+// answers them with -y.
+func journaling() bool {
+	return runConfig != nil && runConfig.Journal && !runConfig.AutoYes
+}
+
+// ask asks question on the prompt line of r and returns the trimmed answer.
+// Without a timer UI, before the first session or once the run has stopped,
+// r is nil and the question is printed plainly. It reports false when the
+// run was interrupted with Ctrl+C or SIGTERM instead of answered.
+func ask(r *ui.Renderer, question string) (string, bool) {
+	stop := make(chan os.Signal, 1)
+	signal.Notify(stop, os.Interrupt, syscall.SIGTERM)
+	defer signal.Stop(stop)
+
+	if r == nil {
+		fmt.Print(question)
+		answer, ok := readAnswer(ui.Keys(), stop)
+		if !ok {
+			fmt.Println()
+		}
+		return answer, ok
+	}
+	r.ShowPrompt(question)
+	answer, ok := readAnswer(ui.Keys(), stop)
+	r.ClearPrompt()
+	return answer, ok
+}
+
+// readAnswer reads one line from keys and returns it trimmed, unless stop
+// fires first.
+func readAnswer(keys <-chan byte, stop <-chan os.Signal) (string, bool) {
+	var line strings.Builder
+	for {
+		select {
+		case b, ok := <-keys:
+			if !ok || b == '\n' || b == '\r' {
+				return strings.TrimSpace(line.String()), true
+			}
+			line.WriteByte(b)
+		case <-stop:
+			return "", false
+		}
+	}
+}
+
+// review asks whether the work session at index achieved its intention and
+// what was done in it, then records the session with the answers. When the
+// run is interrupted during the questions, the session is recorded with
+// what was answered so far and review reports false.
+func review(r *ui.Renderer, engine *timer.Engine, index int) bool {
+	ok := true
+	if engine.Sessions[index].Type == timer.WORK {
+		ok = askOutcome(r, engine, index) && askJournal(r, engine, index)
+	}
+	engine.RecordSession(index)
+	return ok
+}
+
+// askJournal saves the answer to journalQuestion with the session at
+// index. An empty answer saves nothing. It reports false when the run was
+// interrupted.
+func askJournal(r *ui.Renderer, engine *timer.Engine, index int) bool {
+	if !journaling() {
+		return true
+	}
+	note, ok := ask(r, journalQuestion)
+	if note != "" {
+		engine.SetNote(index, note)
+	}
+	return ok
+}
+
+// stoppedWork returns the index of the work session cut short by stopping
+// the run, or -1 when the run stopped between sessions or during a break.
+func stoppedWork(engine *timer.Engine) int {
+	index := len(engine.Sessions) - 1
+	if index < 0 {
+		return -1
+	}
+	s := engine.Sessions[index]
+	if s.Type != timer.WORK || !s.WasCancelled {
+		return -1
+	}
+	return index
+}
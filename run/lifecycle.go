@@ -0,0 +1,173 @@
+package run
+
+import (
+	"time"
+
+	"termidoro/config"
+	"termidoro/history"
+	"termidoro/hooks"
+	"termidoro/notify"
+	"termidoro/timer"
+)
+
+// lifecycle runs the user's hook commands; nil when none are configured.
+var lifecycle *hooks.Runner
+
+// notifiers delivers desktop, sound, bell, terminal and webhook
+// notifications.
+var notifiers *notify.Registry
+
+// runConfig is the configuration of the current run, used to work out the
+// phase that follows a session.
+var runConfig *config.Config
+
+// completedToday counts today's completed work sessions, including those
+// of earlier runs, for the notification text.
+var completedToday int
+
+func countCompletedToday(records []history.Record, now time.Time) int {
+	y, m, d := now.Date()
+	midnight := time.Date(y, m, d, 0, 0, 0, 0, now.Location())
+	count := 0
+	for _, r := range records {
+		if r.Type == timer.TypeName(timer.WORK) && r.Completed && !r.StartTime.Before(midnight) {
+			count++
+		}
+	}
+	return count
+}
+
+// nextPhase returns the type and length of the session that follows s.
+func nextPhase(cfg *config.Config, s timer.Session) (timer.SessionType, time.Duration) {
+	if s.Type.IsBreak() {
+		return timer.WORK, cfg.WorkDuration
+	}
+	if isLongBreak(cfg, s.Cycle) {
+		return timer.LONG_BREAK, cfg.LongBreakDuration
+	}
+	return timer.BREAK, cfg.BreakDuration
+}
+
+func newNotifiers(cfg *config.Config) *notify.Registry {
+	sound := notify.Sound{
+		Players:    cfg.SoundPlayers,
+		Sounds:     cfg.Sounds,
+		Tick:       cfg.Tick,
+		TickBreaks: cfg.TickBreaks,
+		Countdown:  cfg.Countdown,
+	}
+	r := notify.NewRegistry(sound, notify.Desktop{}, notify.Bell{}, cfg.Terminal, notify.Webhooks(cfg.Webhooks))
+	r.SetEnabled("sound", cfg.SoundEnabled)
+	r.SetEnabled("desktop", cfg.DesktopEnabled)
+	r.SetEnabled("bell", cfg.BellEnabled)
+	r.SetEnabled("terminal", cfg.TerminalEnabled)
+	r.SetEnabled("webhook", len(cfg.Webhooks) > 0)
+	r.SetTexts(cfg.Texts)
+	r.SetPolicy(cfg.Policy)
+	return r
+}
+
+// publish reports a session event to every observer of the run: the JSON
+// event stream, the lifecycle hooks and the notifiers.
+func publish(name string, engine *timer.Engine, index int) {
+	events.session(name, engine, index)
+	runHooks(name, engine.Sessions[index], index)
+	sendNotifications(name, engine.Sessions[index], index)
+}
+
+// sendNotifications hands session start and end events to the notifiers.
+func sendNotifications(name string, s timer.Session, index int) {
+	var t notify.EventType
+	switch name {
+	case eventSessionStarted:
+		t = notify.SessionStarted
+	case eventSessionCompleted:
+		t = notify.SessionCompleted
+	case eventSessionSkipped:
+		t = notify.SessionSkipped
+	case eventSessionCancelled:
+		t = notify.SessionCancelled
+	case eventSessionVoided:
+		t = notify.SessionVoided
+	default:
+		return
+	}
+	ev := notify.Event{
+		Type:    t,
+		Session: s,
+		Number:  index + 1,
+		Cycle:   s.Cycle,
+		Time:    s.StartTime,
+	}
+	if s.IsFinished() {
+		ev.Time = s.EndTime
+	}
+	if t == notify.SessionCompleted && s.Type == timer.WORK {
+		completedToday++
+	}
+	ev.CompletedToday = completedToday
+	if runConfig != nil {
+		ev.Next, ev.NextDuration = nextPhase(runConfig, s)
+	}
+	if t == notify.SessionCompleted {
+		offerActions(&ev)
+	}
+	notifiers.Notify(ev)
+}
+
+// notifyTick reports a second of countdown for ticking and countdown
+// beeps.
+func notifyTick(engine *timer.Engine, index int, remaining time.Duration) {
+	s := engine.Sessions[index]
+	notifiers.Notify(notify.Event{
+		Type:      notify.SessionTick,
+		Session:   s,
+		Number:    index + 1,
+		Cycle:     s.Cycle,
+		Remaining: remaining,
+	})
+}
+
+func runHooks(name string, s timer.Session, index int) {
+	if lifecycle == nil {
+		return
+	}
+	info := hooks.Info{
+		Type:     timer.TypeName(s.Type),
+		Name:     s.Name,
+		Duration: s.Duration,
+		Cycle:    s.Cycle,
+		Session:  index + 1,
+	}
+	if s.IsFinished() {
+		info.Duration = s.Elapsed
+	}
+
+	switch name {
+	case eventSessionStarted:
+		if s.Type == timer.WORK {
+			lifecycle.Run(hooks.WorkStart, info)
+		} else {
+			lifecycle.Run(hooks.BreakStart, info)
+		}
+	case eventSessionCompleted, eventSessionSkipped, eventSessionVoided:
+		info.Status = "completed"
+		if s.Skipped {
+			info.Status = "skipped"
+		} else if s.Voided {
+			info.Status = "voided"
+		}
+		if s.Type == timer.WORK {
+			lifecycle.Run(hooks.WorkEnd, info)
+		} else {
+			lifecycle.Run(hooks.BreakEnd, info)
+			lifecycle.Run(hooks.CycleEnd, info)
+		}
+	case eventSessionCancelled:
+		lifecycle.Run(hooks.Cancel, info)
+	case eventSessionPaused:
+		lifecycle.Run(hooks.Pause, info)
+	case eventSessionResumed:
+		lifecycle.Run(hooks.Resume, info)
+	}
+}
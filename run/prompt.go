@@ -0,0 +1,119 @@
+package run
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"termidoro/control"
+	"termidoro/notify"
+	"termidoro/ui"
+)
+
+// snoozeStep is how long the Snooze button puts off the next session.
+const snoozeStep = 5 * time.Minute
+
+// answers receives the key of the button clicked on a session-end
+// notification. It is nil unless notification actions are on.
+var answers chan string
+
+// answered is closed once the prompt after a completed session is
+// answered, which withdraws the buttons of its notification. It is nil
+// while no prompt is pending.
+var answered chan struct{}
+
+// lastCompleted is the notification whose buttons are pending, sent again
+// when a snooze runs out.
+var lastCompleted notify.Event
+
+// offerActions adds the buttons that answer the next prompt to the
+// notification of a completed session.
+func offerActions(ev *notify.Event) {
+	if answers == nil {
+		return
+	}
+	endPrompt()
+	start := notify.Action{Key: notify.ActionContinue, Label: "Start break"}
+	if ev.Session.Type.IsBreak() {
+		start.Label = "Start work"
+	}
+	ev.Actions = []notify.Action{
+		start,
+		{Key: notify.ActionSnooze, Label: fmt.Sprintf("Snooze %dm", int(snoozeStep/time.Minute))},
+		{Key: notify.ActionStop, Label: "Stop"},
+	}
+	answered = make(chan struct{})
+	ev.Reply = answers
+	ev.Done = answered
+	lastCompleted = *ev
+}
+
+// awaitStart waits for the go-ahead to start the break that follows a
+// completed work session. Without notification actions, and after a
+// skipped session, the break starts right away.
+func awaitStart(r *ui.Renderer, message string) bool {
+	if answers == nil || answered == nil {
+		return true
+	}
+	return awaitNext(r, message+" Start it? [Y/n]: ")
+}
+
+// promptContinue asks whether to start another cycle.
+func promptContinue(r *ui.Renderer) bool {
+	return awaitNext(r, "Continue with another cycle? [Y/n]: ")
+}
+
+// awaitNext asks question and takes the answer from the keyboard, from a
+// button on the notification of the session that just ended or from a stop
+// sent to the control socket. It reports whether the run continues.
+func awaitNext(r *ui.Renderer, question string) bool {
+	defer endPrompt()
+	r.ShowPrompt(question)
+	defer r.HidePrompt()
+
+	var line strings.Builder
+	var snoozed <-chan time.Time
+	keys := ui.Keys()
+	for {
+		select {
+		case b, ok := <-keys:
+			if !ok {
+				return true
+			}
+			if b != '\n' && b != '\r' {
+				line.WriteByte(b)
+				continue
+			}
+			return !ui.Declined(line.String())
+		case key := <-answers:
+			switch key {
+			case notify.ActionContinue:
+				return true
+			case notify.ActionStop:
+				return false
+			case notify.ActionSnooze:
+				r.DisplayMessage("Snoozed until " + time.Now().Add(snoozeStep).Format("15:04"))
+				snoozed = time.After(snoozeStep)
+			}
+		case cmd := <-controlCommands():
+			if cmd.Name == control.CmdStop {
+				cmd.Reply(nil)
+				return false
+			}
+			cmd.Reply(errors.New("no session is running"))
+		case <-snoozed:
+			snoozed = nil
+			r.ClearMessage()
+			notifiers.NotifyVia(lastCompleted, []string{"desktop", "sound"})
+		}
+	}
+}
+
+// endPrompt withdraws the buttons of a pending notification.
+func endPrompt() {
+	if answered != nil {
+		close(answered)
+		answered = nil
+	}
+}
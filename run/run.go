@@ -10,50 +10,155 @@ import (
 	"syscall"
 	"time"
 
+	"termidoro/config"
+	"termidoro/control"
+	"termidoro/history"
+	"termidoro/hooks"
 	"termidoro/notify"
 	"termidoro/timer"
 	"termidoro/ui"
 )
 
+// adjustStep is how much the + and - keys add to or remove from a session.
+const adjustStep = 5 * time.Minute
+
 var (
-	cachedWorkDuration  time.Duration
-	cachedBreakDuration time.Duration
-	durationsSet        bool
+	cachedWorkDuration      time.Duration
+	cachedBreakDuration     time.Duration
+	cachedLongBreakDuration time.Duration
+	durationsSet            bool
 )
 
-func Timer(workDuration, breakDuration time.Duration, customWorkName string, autoYes bool) {
-	cachedWorkDuration = workDuration
-	cachedBreakDuration = breakDuration
+// Timer runs work sessions and breaks until the user stops. It only fails
+// when the run cannot start.
+func Timer(cfg *config.Config) error {
+	cachedWorkDuration = cfg.WorkDuration
+	cachedBreakDuration = cfg.BreakDuration
+	cachedLongBreakDuration = cfg.LongBreakDuration
 	durationsSet = true
 
+	customWorkName := cfg.CustomName
+	autoYes := cfg.AutoYes
+
+	events = nil
+	if cfg.Output == config.OutputJSON {
+		// stdout belongs to the event stream; keep escape codes out of it.
+		events = newEmitter(os.Stdout, cfg.JSONInterval)
+		ui.SetOutput(io.Discard)
+		notify.SetTerminal(io.Discard)
+	}
+
 	engine := timer.NewEngine()
+	var records []history.Record
+	if path, err := history.DefaultPath(); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: Session history disabled: %v\n", err)
+	} else {
+		store := history.NewStore(path)
+		engine.SetRecorder(store)
+		if records, err = store.Load(); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: Could not read the session history: %v\n", err)
+		}
+	}
+	completedToday = countCompletedToday(records, time.Now())
+
+	t, err := chooseTask(cfg, records)
+	if err != nil {
+		return err
+	}
+	currentTask, taskPomodoros = t, 0
+	if t != nil {
+		engine.Task = t.ID
+		taskPomodoros = history.CountPomodoros(records)[t.ID]
+		if customWorkName == "" {
+			customWorkName = t.Name
+		}
+	}
+	engine.Name = customWorkName
+	lifecycle = nil
+	if len(cfg.Hooks) > 0 {
+		runner, err := hooks.New(cfg.Hooks)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: Hooks disabled: %v\n", err)
+		} else {
+			lifecycle = runner
+			defer runner.Wait(5 * time.Second)
+		}
+	}
+
+	runConfig = cfg
+	// Work sessions are recorded by review once their questions are answered.
+	engine.HoldWork = journaling() || intending()
+	notifiers = newNotifiers(cfg)
+	alerts = cfg.Alerts
+	answers = nil
+	if cfg.DesktopActions && cfg.DesktopEnabled && !autoYes {
+		answers = make(chan string, 1)
+	}
+	// Deferred first so the tone files outlive the players still running.
+	defer notify.RemoveToneFiles()
+	defer notify.Wait(5 * time.Second)
+
+	if server, err := control.Listen(control.SocketPath()); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: Remote control disabled: %v\n", err)
+	} else {
+		controller = server
+		defer server.Close()
+	}
+
 	sessionNum := 1
 	cycleNum := 1
+	// prompts asks the questions before a work session on its prompt line.
+	// There is no timer UI before the first session.
+	var prompts *ui.Renderer
 
 	for {
+		engine.Cycle = cycleNum
+		events.cycleStarted(cycleNum)
+
 		// Run WORK session
 		workDuration := getDuration(timer.WORK, autoYes)
-		workCompleted := runSession(engine, sessionNum, workDuration, timer.WORK, cycleNum, customWorkName, autoYes)
+		prompt := prompts
+		workCompleted, next := keepWork(engine, sessionNum,
+			func(sessionNum int) bool {
+				return runWork(prompt, engine, sessionNum, workDuration, cycleNum, customWorkName, autoYes)
+			},
+			// A voided pomodoro is started over within the same cycle.
+			func(sessionNum int, voided timer.Session) bool {
+				prompt = ui.NewRenderer(0, sessionNum-1, timer.WORK, cycleNum, customWorkName)
+				return autoYes || promptRestart(prompt, voided)
+			})
+		sessionNum = next
 		if !workCompleted {
 			printRecap(engine)
 			break
 		}
 		sessionNum++
 
+		breakType := timer.BREAK
+		breakMessage := "Time for a break!"
+		if isLongBreak(cfg, cycleNum) {
+			breakType = timer.LONG_BREAK
+			breakMessage = "Time for a long break!"
+		}
+
 		// Instant transition between sessions
 		workProgress := ui.NewRenderer(0, sessionNum-1, timer.WORK, cycleNum, customWorkName)
 		if !autoYes {
-			workProgress.DisplayMessage("Time for a break!")
+			workProgress.DisplayMessage(breakMessage)
 			workProgress.ClearMessage()
 		} else {
 			// For auto-yes, still show brief transition
-			workProgress.DisplayMessage("Time for a break!")
+			workProgress.DisplayMessage(breakMessage)
 			workProgress.ClearMessage()
 		}
+		if !awaitStart(workProgress, breakMessage) {
+			printRecap(engine)
+			break
+		}
 
 		// Run BREAK session
-		breakDuration := getDuration(timer.BREAK, autoYes)
-		breakCompleted := runSession(engine, sessionNum, breakDuration, timer.BREAK, cycleNum, customWorkName, autoYes)
+		breakDuration := getDuration(breakType, autoYes)
+		breakCompleted := runSession(engine, sessionNum, breakDuration, breakType, cycleNum, customWorkName, autoYes)
 		if !breakCompleted {
 			printRecap(engine)
 			break
@@ -64,21 +169,32 @@ func Timer(workDuration, breakDuration time.Duration, customWorkName string, aut
 		continueProgress := ui.NewRenderer(0, sessionNum-1, timer.WORK, cycleNum, customWorkName)
 		if !autoYes {
 			continueProgress.DisplayMessage("")
-			if !continueProgress.PromptContinue() {
+			if !promptContinue(continueProgress) {
 				printRecap(engine)
 				break
 			}
 			continueProgress.ClearMessage()
 		}
+		prompts = continueProgress
 		cycleNum++
 	}
+	return nil
+}
+
+// isLongBreak reports whether the break that follows the work session of
+// cycleNum should be a long one.
+func isLongBreak(cfg *config.Config, cycleNum int) bool {
+	return cfg.LongBreakDuration > 0 && cfg.LongBreakEvery > 0 && cycleNum%cfg.LongBreakEvery == 0
 }
 
 func getDuration(sessionType timer.SessionType, autoYes bool) time.Duration {
 	if durationsSet {
-		if sessionType == timer.WORK {
+		switch sessionType {
+		case timer.WORK:
 			return cachedWorkDuration
-		} else {
+		case timer.LONG_BREAK:
+			return cachedLongBreakDuration
+		default:
 			return cachedBreakDuration
 		}
 	}
@@ -146,94 +262,236 @@ func getDuration(sessionType timer.SessionType, autoYes bool) time.Duration {
 
 	durationsSet = true
 
-	if sessionType == timer.WORK {
+	switch sessionType {
+	case timer.WORK:
 		return cachedWorkDuration
+	case timer.LONG_BREAK:
+		return cachedLongBreakDuration
+	default:
+		return cachedBreakDuration
 	}
-	return cachedBreakDuration
+}
+
+// runWork asks for the intention of a work session on the prompt line of r,
+// runs the session and, unless the run was stopped, reviews it. It reports
+// whether the run continues.
+func runWork(r *ui.Renderer, engine *timer.Engine, sessionNum int, duration time.Duration, cycleNum int, customWorkName string, autoYes bool) bool {
+	intention, ok := askIntention(r)
+	if !ok {
+		return false
+	}
+	engine.Intention = intention
+	if !runSession(engine, sessionNum, duration, timer.WORK, cycleNum, customWorkName, autoYes) {
+		return false
+	}
+	return review(ui.NewRenderer(0, sessionNum, timer.WORK, cycleNum, customWorkName), engine, sessionNum-1)
 }
 
 func runSession(engine *timer.Engine, sessionNum int, duration time.Duration, sessionType timer.SessionType, cycleNum int, customWorkName string, autoYes bool) bool {
-	engine.AddSession(duration)
-	totalSeconds := int64(duration.Seconds())
-	progress := ui.NewRenderer(totalSeconds, sessionNum, sessionType, cycleNum, customWorkName)
+	s := startSession(engine, duration, sessionType)
+	index := s.index
+	progress := ui.NewRenderer(int64(duration.Seconds()), sessionNum, sessionType, cycleNum, customWorkName)
 
 	c := make(chan os.Signal, 1)
 	signal.Notify(c, os.Interrupt, syscall.SIGTERM)
+	defer signal.Stop(c)
 
-	cancelled := make(chan bool, 1)
+	// In raw mode Ctrl+C arrives as a key press instead of SIGINT. Keys are
+	// only read when the terminal is in raw mode so piped input is left for
+	// the prompts.
+	var keys <-chan byte
+	restoreTerminal := func() {}
+	if restore, err := ui.EnableRawMode(); err == nil {
+		restoreTerminal = restore
+		keys = ui.Keys()
+	}
+	defer func() { restoreTerminal() }()
+	defer publishWaiting()
+
+	ticker := time.NewTicker(time.Second)
+	resizeTicker := time.NewTicker(500 * time.Millisecond) // Check for resizes every 500ms
+	defer ticker.Stop()
+	defer resizeTicker.Stop()
 
-	go func() {
-		<-c
-		fmt.Print("\033[?25h")
-		progress.CancelledMessage(sessionNum, cycleNum)
-		engine.CancelSession(sessionNum - 1)
-		cancelled <- true
-	}()
+	// apply carries out a keyboard or control action and brings the timer
+	// UI up to date. It reports whether the session has ended and, if so,
+	// whether the run continues.
+	apply := func(a action, delta time.Duration) (ended, next bool) {
+		if s.note.active && (a == actionCancel || a == actionSkip) {
+			progress.ClearPrompt()
+		}
+		if a == actionCancel {
+			restoreTerminal()
+			restoreTerminal = func() {}
+			progress.RestoreCursor()
+			progress.CancelledMessage(sessionNum, cycleNum)
+		}
+		noting, paused := s.note.active, s.paused
+		if ended, next := s.apply(a, delta); ended {
+			return true, next
+		}
+		switch {
+		case paused != s.paused:
+			progress.SetPaused(s.paused)
+			if !s.paused {
+				ticker.Reset(time.Second)
+			}
+		case a == actionRestart:
+			progress.Reset()
+		case s.note.active && !noting:
+			progress.SetInterruptions(s.interruptions(), s.voidAfter)
+			progress.ShowPrompt(notePrompt)
+		}
+		progress.SetTotal(int64(s.duration.Seconds()))
+		progress.DrawTimeLeft(s.elapsed, s.duration)
+		publishStatus(engine, index, s.elapsed, s.duration)
+		return false, false
+	}
 
 	progress.Start()
+	if intention := engine.Sessions[index].Intention; intention != "" {
+		progress.SetIntention(intention)
+	}
 
 	defer progress.RestoreCursor()
 
-	ticker := time.NewTicker(time.Second)
-	resizeTicker := time.NewTicker(500 * time.Millisecond) // Check for resizes every 500ms
-	defer ticker.Stop()
-	defer resizeTicker.Stop()
+	publishStatus(engine, index, 0, s.duration)
 
 	for {
 		select {
 		case <-ticker.C:
-			current := progress.GetCurrent()
-
+			if !s.advance() {
+				continue
+			}
 			progress.Increment()
-			elapsed := time.Duration(current) * time.Second
-			progress.DrawTimeLeft(elapsed, duration)
+			progress.DrawTimeLeft(s.elapsed, s.duration)
+			events.tick(engine, index, s.elapsed, s.duration)
+			publishStatus(engine, index, s.elapsed, s.duration)
+			raiseAlerts(progress, engine, index, s.elapsed, s.duration)
+			notifyTick(engine, index, s.duration-s.elapsed)
 
-			if current >= int(totalSeconds-1) && current < int(totalSeconds) {
-				if sessionType == timer.WORK {
-					notify.PlayWorkCompleteSound()
-				} else {
-					notify.PlayBreakCompleteSound()
+			if s.due() {
+				if s.note.active {
+					progress.ClearPrompt()
 				}
-			}
-			if current >= int(totalSeconds) {
+				s.complete()
 				return true
 			}
 		case <-resizeTicker.C:
 			progress.UpdateTerminalSize()
 			// Redraw current time left with updated terminal size
-			current := progress.GetCurrent()
-			elapsed := time.Duration(current) * time.Second
-			progress.DrawTimeLeft(elapsed, duration)
-		case <-cancelled:
-			return false
+			progress.DrawTimeLeft(s.elapsed, s.duration)
+			if s.note.active {
+				progress.ShowPrompt(notePrompt + s.note.String())
+			}
+		case key, ok := <-keys:
+			if !ok {
+				keys = nil
+				continue
+			}
+			if s.note.active && key != ui.KeyCtrlC {
+				voided := s.noteKey(key)
+				if s.note.active {
+					progress.ShowPrompt(notePrompt + s.note.String())
+				} else {
+					progress.ClearPrompt()
+				}
+				if voided {
+					return true
+				}
+				continue
+			}
+			if ended, next := apply(keyAction(key)); ended {
+				return next
+			}
+		case cmd := <-controlCommands():
+			a, delta := commandAction(cmd)
+			ended, next := apply(a, delta)
+			cmd.Reply(nil)
+			if ended {
+				return next
+			}
+		case <-c:
+			_, next := apply(actionCancel, 0)
+			return next
 		}
 	}
 }
 
 func printRecap(engine *timer.Engine) {
-	sessions := make([]struct {
-		Duration  string
-		StartTime string
-		EndTime   string
-		Completed bool
-		Cancelled bool
-	}, len(engine.Sessions))
+	engine.CancelOpenSessions()
+	if index := stoppedWork(engine); index >= 0 {
+		review(nil, engine, index)
+	}
+	// Sessions still held for review, if any, are recorded as they are.
+	for i := range engine.Sessions {
+		engine.RecordSession(i)
+	}
+	if events != nil {
+		events.recap(engine)
+		return
+	}
+	sessions := make([]ui.RecapEntry, len(engine.Sessions))
+	var internal, external, work int
 
 	for i, s := range engine.Sessions {
-		sessions[i] = struct {
-			Duration  string
-			StartTime string
-			EndTime   string
-			Completed bool
-			Cancelled bool
-		}{
-			Duration:  timer.FormatDuration(s.Duration),
+		sessions[i] = ui.RecapEntry{
+			Type:      s.Type.String(),
+			Duration:  timer.FormatDuration(s.Elapsed),
 			StartTime: s.StartTime.Format("15:04"),
 			EndTime:   s.EndTime.Format("15:04"),
 			Completed: s.Completed,
 			Cancelled: s.WasCancelled,
 		}
+		if !s.Completed {
+			sessions[i].Planned = timer.FormatDuration(s.Duration)
+		}
+		if s.Paused > 0 {
+			sessions[i].Paused = timer.FormatDuration(s.Paused)
+		}
+		if s.Extended != 0 {
+			sessions[i].Extended = formatAdjustment(s.Extended)
+		}
+		sessions[i].Restarts = s.Restarts
+		sessions[i].Skipped = s.Skipped
+		sessions[i].Voided = s.Voided
+		sessions[i].Note = s.Note
+		sessions[i].Intention = s.Intention
+		sessions[i].Outcome = s.Outcome
+		sessions[i].Interruptions = len(s.Interruptions)
+		if s.Type == timer.WORK {
+			in, ex := s.InterruptionCounts()
+			internal += in
+			external += ex
+			work++
+		}
 	}
 
 	ui.PrintRecap(sessions, engine.TotalTime)
+	ui.PrintInterruptionRecap(internal, external, work)
+	printTaskRecap(completedWork(engine))
+}
+
+// completedWork counts the completed work sessions of the run.
+func completedWork(engine *timer.Engine) int {
+	n := 0
+	for _, s := range engine.Sessions {
+		if s.Type == timer.WORK && s.Completed {
+			n++
+		}
+	}
+	return n
+}
+
+// formatAdjustment renders a signed duration change such as "+5m" or "-4m 59s".
+func formatAdjustment(d time.Duration) string {
+	sign := "+"
+	if d < 0 {
+		sign = "-"
+		d = -d
+	}
+	if d%time.Minute == 0 {
+		return sign + timer.FormatDurationMinutes(d)
+	}
+	return sign + timer.FormatDuration(d)
 }
@@ -0,0 +1,164 @@
+package run
+
+import (
+	"time"
+
+	"termidoro/timer"
+)
+
+// session holds the rules of the running session: how the ticker, the
+// keyboard and the control socket change it. runSession draws the timer UI
+// from its state, which keeps the rules testable without a terminal.
+type session struct {
+	engine   *timer.Engine
+	index    int
+	kind     timer.SessionType
+	duration time.Duration
+	// elapsed counts the ticks of the session while it is not paused.
+	elapsed time.Duration
+	paused  bool
+	// voidAfter is the number of interruptions the session may have before
+	// it is voided, or 0 for no limit.
+	voidAfter int
+	// note is the interruption note being typed, saved before the session
+	// ends.
+	note noteInput
+}
+
+// startSession adds a session of the given type and length to engine and
+// announces it.
+func startSession(engine *timer.Engine, duration time.Duration, kind timer.SessionType) *session {
+	engine.AddSession(duration, kind)
+	s := &session{
+		engine:    engine,
+		index:     len(engine.Sessions) - 1,
+		kind:      kind,
+		duration:  duration,
+		voidAfter: voidLimit(),
+	}
+	publish(eventSessionStarted, engine, s.index)
+	return s
+}
+
+// advance counts one tick, unless the session is paused. It reports
+// whether the tick was counted.
+func (s *session) advance() bool {
+	if s.paused {
+		return false
+	}
+	s.elapsed += time.Second
+	return true
+}
+
+// due reports whether the session has run its full length, counted in
+// whole seconds.
+func (s *session) due() bool {
+	return s.elapsed >= s.duration.Truncate(time.Second)
+}
+
+func (s *session) complete() {
+	s.saveNote()
+	s.engine.CompleteSession(s.index)
+	publish(eventSessionCompleted, s.engine, s.index)
+}
+
+// apply carries out a keyboard or control action. It reports whether the
+// session has ended and, if so, whether the run continues.
+func (s *session) apply(a action, delta time.Duration) (ended, next bool) {
+	switch a {
+	case actionCancel:
+		s.saveNote()
+		s.engine.CancelSession(s.index)
+		publish(eventSessionCancelled, s.engine, s.index)
+		return true, false
+	case actionSkip:
+		s.saveNote()
+		s.engine.SkipSession(s.index)
+		publish(eventSessionSkipped, s.engine, s.index)
+		return true, true
+	case actionToggle, actionPause, actionResume:
+		if s.paused && a != actionPause {
+			s.engine.ResumeSession(s.index)
+			s.paused = false
+			publish(eventSessionResumed, s.engine, s.index)
+		} else if !s.paused && a != actionResume {
+			s.engine.PauseSession(s.index)
+			s.paused = true
+			publish(eventSessionPaused, s.engine, s.index)
+		}
+	case actionExtend:
+		if delta < 0 {
+			// Never shorten the session below what has already elapsed.
+			if s.duration+delta <= s.elapsed {
+				delta = s.elapsed + time.Second - s.duration
+			}
+			if delta >= 0 {
+				delta = 0
+			}
+		}
+		if delta != 0 {
+			s.engine.ExtendSession(s.index, delta)
+			s.duration += delta
+		}
+	case actionRestart:
+		s.engine.RestartSession(s.index)
+		s.elapsed = 0
+	case actionInterrupt, actionInterruptExternal:
+		if s.kind != timer.WORK || s.note.active {
+			break
+		}
+		s.engine.Interrupt(s.index, a == actionInterruptExternal)
+		s.note.start()
+	}
+	return false, false
+}
+
+// noteKey adds a key press to the interruption note. Once the note is
+// finished it is saved, and the session is voided if it has now had too
+// many interruptions. It reports whether the session was voided.
+func (s *session) noteKey(b byte) bool {
+	if !s.note.key(b) {
+		return false
+	}
+	s.saveNote()
+	return s.voidIfOverLimit()
+}
+
+// saveNote attaches the note being typed to the latest interruption.
+func (s *session) saveNote() {
+	if s.note.active {
+		s.engine.NoteInterruption(s.index, s.note.finish())
+	}
+}
+
+// voidIfOverLimit voids the session once it has more interruptions than
+// the limit. It reports whether it did.
+func (s *session) voidIfOverLimit() bool {
+	if s.voidAfter == 0 || s.interruptions() <= s.voidAfter {
+		return false
+	}
+	s.engine.VoidSession(s.index)
+	publish(eventSessionVoided, s.engine, s.index)
+	return true
+}
+
+func (s *session) interruptions() int {
+	return len(s.engine.Sessions[s.index].Interruptions)
+}
+
+// keepWork runs work sessions from sessionNum until one is not voided for
+// interruptions. After each voided session, again is asked whether to start
+// a new one; a no ends the run. It returns whether the run continues and
+// the number of the last session.
+func keepWork(engine *timer.Engine, sessionNum int, work func(sessionNum int) bool, again func(sessionNum int, voided timer.Session) bool) (bool, int) {
+	kept := work(sessionNum)
+	for kept && lastVoided(engine) {
+		voided := engine.Sessions[sessionNum-1]
+		sessionNum++
+		if !again(sessionNum, voided) {
+			return false, sessionNum
+		}
+		kept = work(sessionNum)
+	}
+	return kept, sessionNum
+}
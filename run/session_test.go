@@ -0,0 +1,211 @@
+package run
+
+import (
+	"testing"
+	"time"
+
+	"termidoro/timer"
+)
+
+// fakeClock returns a clock for the engine and a function advancing it.
+func fakeClock() (func() time.Time, func(time.Duration)) {
+	now := time.Date(2025, 3, 10, 9, 0, 0, 0, time.UTC)
+	return func() time.Time { return now }, func(d time.Duration) { now = now.Add(d) }
+}
+
+// newTestSession starts a work session on an engine with a fake clock. tick
+// lets one second pass for the engine and the session alike.
+func newTestSession(duration time.Duration) (s *session, tick func(n int)) {
+	engine := timer.NewEngine()
+	clock, advance := fakeClock()
+	engine.SetClock(clock)
+	s = startSession(engine, duration, timer.WORK)
+	return s, func(n int) {
+		for range n {
+			advance(time.Second)
+			s.advance()
+		}
+	}
+}
+
+func TestSessionExtend(t *testing.T) {
+	tests := []struct {
+		name     string
+		elapsed  int
+		delta    time.Duration
+		want     time.Duration
+		extended time.Duration
+	}{
+		{"add", 60, 5 * time.Minute, 15 * time.Minute, 5 * time.Minute},
+		{"remove", 60, -5 * time.Minute, 5 * time.Minute, -5 * time.Minute},
+		{"clamp to elapsed", 7 * 60, -5 * time.Minute, 7*time.Minute + time.Second, -2*time.Minute - 59*time.Second},
+		{"nothing left to remove", 10*60 - 1, -5 * time.Minute, 10 * time.Minute, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, tick := newTestSession(10 * time.Minute)
+			tick(tt.elapsed)
+			if ended, _ := s.apply(actionExtend, tt.delta); ended {
+				t.Fatal("Expected the session to keep running")
+			}
+			got := s.engine.Sessions[0]
+			if s.duration != tt.want || got.Duration != tt.want || got.Extended != tt.extended {
+				t.Errorf("Expected %v planned and %v extended, got %v (engine %v) and %v", tt.want, tt.extended, s.duration, got.Duration, got.Extended)
+			}
+			if s.due() {
+				t.Error("Expected a shortened session not to be due yet")
+			}
+		})
+	}
+}
+
+func TestSessionPauseAndRestart(t *testing.T) {
+	s, tick := newTestSession(2 * time.Minute)
+	tick(30)
+	s.apply(actionToggle, 0)
+	tick(10)
+	if s.elapsed != 30*time.Second || !s.engine.Sessions[0].IsPaused() {
+		t.Errorf("Expected the paused session to stand at 30s, got %v", s.elapsed)
+	}
+	s.apply(actionPause, 0)
+	s.apply(actionResume, 0)
+	s.apply(actionResume, 0)
+	if s.paused || s.engine.Sessions[0].Paused != 10*time.Second {
+		t.Errorf("Expected one 10s pause, got %v", s.engine.Sessions[0].Paused)
+	}
+
+	s.apply(actionRestart, 0)
+	tick(119)
+	if s.due() || s.engine.Sessions[0].Restarts != 1 {
+		t.Fatalf("Expected the restarted session to run 2 more minutes, got %v", s.elapsed)
+	}
+	tick(1)
+	if !s.due() {
+		t.Fatal("Expected the session to be due")
+	}
+	s.complete()
+	if got := s.engine.Sessions[0]; !got.Completed || got.Elapsed != 150*time.Second {
+		t.Errorf("Expected 2m30s focused, got %+v", got)
+	}
+}
+
+func TestSessionInterruptions(t *testing.T) {
+	tests := []struct {
+		name      string
+		voidAfter int
+		notes     []string
+		voided    bool
+	}{
+		{"no limit", 0, []string{"mail", "call", "chat"}, false},
+		{"at the limit", 2, []string{"mail", "call"}, false},
+		{"over the limit", 2, []string{"mail", "call", "chat"}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, tick := newTestSession(25 * time.Minute)
+			s.voidAfter = tt.voidAfter
+			var voided bool
+			for i, note := range tt.notes {
+				tick(60)
+				a := actionInterrupt
+				if i%2 == 1 {
+					a = actionInterruptExternal
+				}
+				s.apply(a, 0)
+				// A second interruption while the note is open is ignored.
+				s.apply(actionInterrupt, 0)
+				for _, b := range []byte(note) {
+					if s.noteKey(b) {
+						t.Fatal("Expected the note to stay open")
+					}
+				}
+				voided = s.noteKey('\r')
+			}
+
+			got := s.engine.Sessions[0]
+			if voided != tt.voided || got.Voided != tt.voided {
+				t.Errorf("Expected voided %v, got %v (engine %v)", tt.voided, voided, got.Voided)
+			}
+			if len(got.Interruptions) != len(tt.notes) {
+				t.Fatalf("Expected %d interruptions, got %+v", len(tt.notes), got.Interruptions)
+			}
+			for i, note := range tt.notes {
+				if in := got.Interruptions[i]; in.Note != note || in.External != (i%2 == 1) {
+					t.Errorf("Unexpected interruption %d: %+v", i, in)
+				}
+			}
+		})
+	}
+
+	// A note being typed is saved when the session ends.
+	s, _ := newTestSession(25 * time.Minute)
+	s.apply(actionInterruptExternal, 0)
+	s.noteKey('x')
+	if ended, next := s.apply(actionSkip, 0); !ended || !next {
+		t.Fatal("Expected skip to end the session and continue the run")
+	}
+	if got := s.engine.Sessions[0].Interruptions; len(got) != 1 || got[0].Note != "x" {
+		t.Errorf("Expected the open note to be saved, got %+v", got)
+	}
+
+	// Breaks take no interruptions.
+	engine := timer.NewEngine()
+	b := startSession(engine, 5*time.Minute, timer.BREAK)
+	b.apply(actionInterrupt, 0)
+	if b.note.active || len(engine.Sessions[0].Interruptions) != 0 {
+		t.Error("Expected no interruption during a break")
+	}
+}
+
+func TestKeepWork(t *testing.T) {
+	tests := []struct {
+		name     string
+		outcomes string // per work session: k kept, v voided, c cancelled
+		again    bool
+		kept     bool
+		last     int
+	}{
+		{"kept", "k", true, true, 1},
+		{"cancelled", "c", true, false, 1},
+		{"voided then kept", "vvk", true, true, 3},
+		{"voided then cancelled", "vc", true, false, 2},
+		{"voided and declined", "v", false, false, 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			engine := timer.NewEngine()
+			var asked []int
+			work := func(sessionNum int) bool {
+				if len(engine.Sessions) != sessionNum-1 {
+					t.Fatalf("Expected session %d to follow %d sessions", sessionNum, len(engine.Sessions))
+				}
+				s := startSession(engine, 25*time.Minute, timer.WORK)
+				switch tt.outcomes[s.index] {
+				case 'v':
+					s.engine.VoidSession(s.index)
+				case 'c':
+					ended, next := s.apply(actionCancel, 0)
+					return !ended || next
+				default:
+					s.complete()
+				}
+				return true
+			}
+			again := func(sessionNum int, voided timer.Session) bool {
+				if !voided.Voided {
+					t.Errorf("Expected the voided session, got %+v", voided)
+				}
+				asked = append(asked, sessionNum)
+				return tt.again
+			}
+
+			kept, last := keepWork(engine, 1, work, again)
+			if kept != tt.kept || last != tt.last {
+				t.Errorf("Expected %v at session %d, got %v at %d", tt.kept, tt.last, kept, last)
+			}
+			if want := len(tt.outcomes) - 1; tt.again && len(asked) != want {
+				t.Errorf("Expected %d restart questions, got %v", want, asked)
+			}
+		})
+	}
+}
@@ -0,0 +1,79 @@
+package run
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"termidoro/config"
+	"termidoro/history"
+	"termidoro/task"
+	"termidoro/ui"
+)
+
+// currentTask is the task the work sessions of the run count against, or
+// nil.
+var currentTask *history.Task
+
+// taskPomodoros is the number of pomodoros done on currentTask before the
+// run started.
+var taskPomodoros int
+
+// chooseTask finds the task given with --task or, in an interactive run,
+// asks which open task to work on. It returns nil to work without one.
+func chooseTask(cfg *config.Config, records []history.Record) (*history.Task, error) {
+	if cfg.Task == "" && cfg.AutoYes {
+		return nil, nil
+	}
+	path, err := history.DefaultTaskPath()
+	var tasks []history.Task
+	if err == nil {
+		tasks, err = history.NewTaskStore(path).Load()
+	}
+	if err != nil {
+		if cfg.Task != "" {
+			return nil, err
+		}
+		// Without --task the run does not need the task list.
+		fmt.Fprintf(os.Stderr, "Warning: Could not read the task list: %v\n", err)
+		return nil, nil
+	}
+	if cfg.Task != "" {
+		i, err := history.FindTask(tasks, cfg.Task)
+		if err != nil {
+			return nil, err
+		}
+		return &tasks[i], nil
+	}
+
+	var open []history.Task
+	for _, t := range tasks {
+		if !t.Done {
+			open = append(open, t)
+		}
+	}
+	if len(open) == 0 {
+		return nil, nil
+	}
+	task.List(os.Stdout, open, history.CountPomodoros(records), false)
+	for {
+		fmt.Print("Task to work on (ID or name, Enter for none): ")
+		answer := strings.TrimSpace(ui.ReadLine())
+		if answer == "" {
+			return nil, nil
+		}
+		i, err := history.FindTask(open, answer)
+		if err == nil {
+			return &open[i], nil
+		}
+		fmt.Printf("%v\n", err)
+	}
+}
+
+// printTaskRecap shows the pomodoros done on the run's task.
+func printTaskRecap(completed int) {
+	if currentTask == nil {
+		return
+	}
+	ui.PrintTaskRecap(currentTask.Name, taskPomodoros+completed, currentTask.Estimate)
+}
@@ -0,0 +1,27 @@
+package run
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"termidoro/config"
+)
+
+func TestChooseTaskCorruptList(t *testing.T) {
+	data := t.TempDir()
+	t.Setenv("XDG_DATA_HOME", data)
+	if err := os.MkdirAll(filepath.Join(data, "termidoro"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(data, "termidoro", "tasks.json"), []byte("{not json"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	if task, err := chooseTask(&config.Config{}, nil); task != nil || err != nil {
+		t.Errorf("Expected to go on without a task, got %v, %v", task, err)
+	}
+	if _, err := chooseTask(&config.Config{Task: "report"}, nil); err == nil {
+		t.Error("Expected --task to fail on an unreadable task list")
+	}
+}
@@ -0,0 +1,273 @@
+package stats
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"time"
+
+	"termidoro/history"
+	"termidoro/task"
+	"termidoro/timer"
+)
+
+const unnamed = "WORK"
+
+// Summary aggregates the work sessions of a period.
+type Summary struct {
+	Completed int
+	Cancelled int
+	Skipped   int
+	Voided    int
+	Focused   time.Duration
+	ByName    map[string]*Summary
+	// Internal and External count the interruptions of the sessions.
+	Internal int
+	External int
+	// Intentions counts the sessions that set one; Achieved, Partly and
+	// Missed those that were reviewed.
+	Intentions int
+	Achieved   int
+	Partly     int
+	Missed     int
+}
+
+// Sessions is the number of work sessions that finished in any way.
+func (s *Summary) Sessions() int {
+	return s.Completed + s.Cancelled + s.Skipped + s.Voided
+}
+
+// Interruptions is the number of internal and external interruptions.
+func (s *Summary) Interruptions() int {
+	return s.Internal + s.External
+}
+
+// HitRate is the share of reviewed intentions that were achieved, 0-100.
+func (s *Summary) HitRate() float64 {
+	reviewed := s.Achieved + s.Partly + s.Missed
+	if reviewed == 0 {
+		return 0
+	}
+	return float64(s.Achieved) * 100 / float64(reviewed)
+}
+
+// InterruptionRate is the average number of interruptions per work session.
+func (s *Summary) InterruptionRate() float64 {
+	if s.Sessions() == 0 {
+		return 0
+	}
+	return float64(s.Interruptions()) / float64(s.Sessions())
+}
+
+func (s *Summary) AverageLength() time.Duration {
+	if s.Sessions() == 0 {
+		return 0
+	}
+	return s.Focused / time.Duration(s.Sessions())
+}
+
+// CompletionRate is the share of work sessions that were completed, 0-100.
+func (s *Summary) CompletionRate() float64 {
+	if s.Sessions() == 0 {
+		return 0
+	}
+	return float64(s.Completed) * 100 / float64(s.Sessions())
+}
+
+func (s *Summary) add(r history.Record) {
+	if r.Completed {
+		s.Completed++
+	} else if r.Cancelled {
+		s.Cancelled++
+	} else if r.Skipped {
+		s.Skipped++
+	} else if r.Voided {
+		s.Voided++
+	}
+	for _, i := range r.Interruptions {
+		if i.External {
+			s.External++
+		} else {
+			s.Internal++
+		}
+	}
+	if r.Intention != "" {
+		s.Intentions++
+		switch r.Outcome {
+		case timer.OutcomeYes:
+			s.Achieved++
+		case timer.OutcomePartly:
+			s.Partly++
+		case timer.OutcomeNo:
+			s.Missed++
+		}
+	}
+	s.Focused += focusedTime(r)
+}
+
+func focusedTime(r history.Record) time.Duration {
+	if r.Elapsed > 0 {
+		return r.Elapsed
+	}
+	// Records written before elapsed time was stored.
+	d := r.EndTime.Sub(r.StartTime) - r.Paused
+	if d < 0 {
+		return 0
+	}
+	return d
+}
+
+func isWork(r history.Record) bool {
+	return r.Type == timer.TypeName(timer.WORK)
+}
+
+// Summarize aggregates work records that started within [from, to).
+func Summarize(records []history.Record, from, to time.Time) *Summary {
+	summary := &Summary{ByName: map[string]*Summary{}}
+	for _, r := range records {
+		if !isWork(r) || r.StartTime.Before(from) || !r.StartTime.Before(to) {
+			continue
+		}
+		summary.add(r)
+
+		name := r.Name
+		if name == "" {
+			name = unnamed
+		}
+		byName, ok := summary.ByName[name]
+		if !ok {
+			byName = &Summary{}
+			summary.ByName[name] = byName
+		}
+		byName.add(r)
+	}
+	return summary
+}
+
+// Period is a named reporting window.
+type Period struct {
+	Label string
+	From  time.Time
+	To    time.Time
+}
+
+// Periods returns today, the current week (starting Monday) and the
+// current month relative to now.
+func Periods(now time.Time) []Period {
+	day := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	weekday := (int(day.Weekday()) + 6) % 7 // Monday = 0
+	week := day.AddDate(0, 0, -weekday)
+	month := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location())
+
+	return []Period{
+		{Label: "Today (" + day.Format("Mon Jan 2") + ")", From: day, To: day.AddDate(0, 0, 1)},
+		{Label: "This Week (from " + week.Format("Mon Jan 2") + ")", From: week, To: week.AddDate(0, 0, 7)},
+		{Label: "This Month (" + month.Format("January 2006") + ")", From: month, To: month.AddDate(0, 1, 0)},
+	}
+}
+
+func formatHours(d time.Duration) string {
+	d = d.Round(time.Minute)
+	hours := int(d.Hours())
+	minutes := int(d.Minutes()) % 60
+	if hours == 0 {
+		return fmt.Sprintf("%dm", minutes)
+	}
+	return fmt.Sprintf("%dh %02dm", hours, minutes)
+}
+
+// Print writes the daily, weekly and monthly summaries of records to stdout.
+func Print(records []history.Record, now time.Time) {
+	for _, p := range Periods(now) {
+		printSummary(p.Label, Summarize(records, p.From, p.To))
+	}
+}
+
+func printSummary(label string, s *Summary) {
+	fmt.Println()
+	fmt.Printf("--- %s ---\n", label)
+	if s.Sessions() == 0 {
+		fmt.Println("No work sessions recorded.")
+		return
+	}
+
+	fmt.Printf("Pomodoros: %d   Focused: %s   Cancelled: %d   Skipped: %d\n", s.Completed, formatHours(s.Focused), s.Cancelled, s.Skipped)
+	fmt.Printf("Average session: %s   Completion rate: %.0f%%\n", timer.FormatDurationShort(s.AverageLength()), s.CompletionRate())
+	if s.Interruptions() > 0 || s.Voided > 0 {
+		fmt.Printf("Interruptions: %d (%d internal, %d external)   Per session: %.1f   Voided: %d\n",
+			s.Interruptions(), s.Internal, s.External, s.InterruptionRate(), s.Voided)
+	}
+	if s.Intentions > 0 {
+		fmt.Printf("Intentions: %d set, %d achieved, %d partly, %d missed   Hit rate: %.0f%%\n",
+			s.Intentions, s.Achieved, s.Partly, s.Missed, s.HitRate())
+	}
+
+	names := make([]string, 0, len(s.ByName))
+	for name := range s.ByName {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool {
+		return s.ByName[names[i]].Focused > s.ByName[names[j]].Focused
+	})
+
+	fmt.Println()
+	fmt.Println("  Name                  Done  Cancelled  Focused    Average    Rate")
+	fmt.Println("  ─────────────────────────────────────────────────────────────────")
+	for _, name := range names {
+		n := s.ByName[name]
+		fmt.Printf("  %-20s  %4d  %9d  %-9s  %-9s  %3.0f%%\n",
+			task.Truncate(name, 20), n.Completed, n.Cancelled, formatHours(n.Focused),
+			timer.FormatDurationShort(n.AverageLength()), n.CompletionRate())
+	}
+}
+
+// PrintTasks writes the completed pomodoros of each task against its
+// estimate: every open task, and finished tasks worked on since from.
+func PrintTasks(tasks []history.Task, records []history.Record, from time.Time) {
+	recent := map[int]bool{}
+	for _, r := range records {
+		if r.Task != 0 && !r.StartTime.Before(from) {
+			recent[r.Task] = true
+		}
+	}
+	var shown []history.Task
+	for _, t := range tasks {
+		if !t.Done || recent[t.ID] {
+			shown = append(shown, t)
+		}
+	}
+	if len(shown) == 0 {
+		return
+	}
+	fmt.Println()
+	fmt.Println("--- Tasks ---")
+	task.List(os.Stdout, shown, history.CountPomodoros(records), true)
+}
+
+// Run loads the history file and prints the report.
+func Run() error {
+	path, err := history.DefaultPath()
+	if err != nil {
+		return err
+	}
+	records, err := history.NewStore(path).Load()
+	if err != nil {
+		return err
+	}
+	if len(records) == 0 {
+		fmt.Printf("No sessions recorded yet in %s\n", path)
+		return nil
+	}
+	now := time.Now()
+	Print(records, now)
+
+	if path, err := history.DefaultTaskPath(); err == nil {
+		tasks, err := history.NewTaskStore(path).Load()
+		if err != nil {
+			return err
+		}
+		// Finished tasks stay in the report for the month they were worked on.
+		PrintTasks(tasks, records, Periods(now)[2].From)
+	}
+	return nil
+}
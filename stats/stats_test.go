@@ -0,0 +1,95 @@
+package stats
+
+import (
+	"testing"
+	"time"
+
+	"termidoro/history"
+)
+
+func TestSummarize(t *testing.T) {
+	day := time.Date(2025, 3, 12, 0, 0, 0, 0, time.UTC)
+	at := func(hour int) time.Time { return day.Add(time.Duration(hour) * time.Hour) }
+
+	records := []history.Record{
+		{Type: "work", Name: "Deep Work", StartTime: at(9), EndTime: at(9).Add(25 * time.Minute), Completed: true},
+		{Type: "break", Name: "Deep Work", StartTime: at(10), EndTime: at(10).Add(5 * time.Minute), Completed: true},
+		{Type: "work", Name: "Deep Work", StartTime: at(11), EndTime: at(11).Add(10 * time.Minute), Cancelled: true},
+		{Type: "work", StartTime: at(12), EndTime: at(12).Add(25 * time.Minute), Completed: true},
+		{Type: "work", Name: "Deep Work", StartTime: at(-24), EndTime: at(-24).Add(25 * time.Minute), Completed: true},
+	}
+
+	s := Summarize(records, day, day.AddDate(0, 0, 1))
+	if s.Completed != 2 || s.Cancelled != 1 {
+		t.Errorf("Expected 2 completed and 1 cancelled, got %d and %d", s.Completed, s.Cancelled)
+	}
+	if s.Focused != 60*time.Minute {
+		t.Errorf("Expected 60m focused, got %v", s.Focused)
+	}
+	if s.AverageLength() != 20*time.Minute {
+		t.Errorf("Expected 20m average, got %v", s.AverageLength())
+	}
+	if rate := s.CompletionRate(); rate < 66 || rate > 67 {
+		t.Errorf("Expected ~66.7%% completion rate, got %.1f", rate)
+	}
+	if dw := s.ByName["Deep Work"]; dw == nil || dw.Completed != 1 || dw.Cancelled != 1 {
+		t.Errorf("Unexpected Deep Work breakdown: %+v", dw)
+	}
+	if w := s.ByName[unnamed]; w == nil || w.Completed != 1 {
+		t.Errorf("Unexpected unnamed breakdown: %+v", w)
+	}
+}
+
+func TestInterruptionRate(t *testing.T) {
+	start := time.Date(2025, 3, 12, 9, 0, 0, 0, time.UTC)
+	records := []history.Record{
+		{Type: "work", StartTime: start, Completed: true, Interruptions: []history.Interruption{{}, {External: true}}},
+		{Type: "work", StartTime: start.Add(time.Hour), Voided: true, Interruptions: []history.Interruption{{}, {}, {}, {External: true}}},
+		{Type: "break", StartTime: start.Add(2 * time.Hour), Completed: true, Interruptions: []history.Interruption{{}}},
+		{Type: "work", StartTime: start.Add(3 * time.Hour), Completed: true},
+	}
+
+	s := Summarize(records, start, start.AddDate(0, 0, 1))
+	if s.Internal != 4 || s.External != 2 {
+		t.Errorf("Expected 4 internal and 2 external interruptions, got %d and %d", s.Internal, s.External)
+	}
+	if s.Voided != 1 || s.Sessions() != 3 {
+		t.Errorf("Expected 1 voided of 3 sessions, got %d of %d", s.Voided, s.Sessions())
+	}
+	if rate := s.InterruptionRate(); rate != 2 {
+		t.Errorf("Expected 2 interruptions per session, got %.1f", rate)
+	}
+}
+
+func TestHitRate(t *testing.T) {
+	start := time.Date(2025, 3, 12, 9, 0, 0, 0, time.UTC)
+	records := []history.Record{
+		{Type: "work", StartTime: start, Completed: true, Intention: "parser tests", Outcome: "yes"},
+		{Type: "work", StartTime: start.Add(time.Hour), Completed: true, Intention: "docs", Outcome: "partly"},
+		{Type: "work", StartTime: start.Add(2 * time.Hour), Cancelled: true, Intention: "review", Outcome: "no"},
+		{Type: "work", StartTime: start.Add(3 * time.Hour), Completed: true, Intention: "release"},
+		{Type: "work", StartTime: start.Add(4 * time.Hour), Completed: true},
+	}
+
+	s := Summarize(records, start, start.AddDate(0, 0, 1))
+	if s.Intentions != 4 || s.Achieved != 1 || s.Partly != 1 || s.Missed != 1 {
+		t.Errorf("Expected 4 intentions, 1 achieved, 1 partly and 1 missed, got %+v", s)
+	}
+	if rate := s.HitRate(); rate < 33 || rate > 34 {
+		t.Errorf("Expected ~33.3%% hit rate, got %.1f", rate)
+	}
+}
+
+func TestPeriods(t *testing.T) {
+	now := time.Date(2025, 3, 12, 15, 30, 0, 0, time.UTC) // Wednesday
+	periods := Periods(now)
+	if len(periods) != 3 {
+		t.Fatalf("Expected 3 periods, got %d", len(periods))
+	}
+	if want := time.Date(2025, 3, 10, 0, 0, 0, 0, time.UTC); !periods[1].From.Equal(want) {
+		t.Errorf("Expected week to start %v, got %v", want, periods[1].From)
+	}
+	if want := time.Date(2025, 4, 1, 0, 0, 0, 0, time.UTC); !periods[2].To.Equal(want) {
+		t.Errorf("Expected month to end %v, got %v", want, periods[2].To)
+	}
+}
@@ -0,0 +1,193 @@
+package task
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"termidoro/history"
+)
+
+// Subcommands of "termidoro task".
+const (
+	CmdAdd      = "add"
+	CmdList     = "list"
+	CmdDone     = "done"
+	CmdEstimate = "estimate"
+)
+
+var errUsage = errors.New("usage: termidoro task add [--estimate n] <name> | list [--all] | done <task> | estimate <task> <n>")
+
+// Run carries out a task subcommand against the shared task list.
+func Run(args []string) error {
+	if len(args) == 0 {
+		return errUsage
+	}
+	path, err := history.DefaultTaskPath()
+	if err != nil {
+		return err
+	}
+	store := history.NewTaskStore(path)
+
+	var estimate int
+	var all bool
+	fs := flag.NewFlagSet("termidoro task "+args[0], flag.ExitOnError)
+	switch args[0] {
+	case CmdAdd:
+		fs.IntVar(&estimate, "estimate", 0, "Estimated number of pomodoros")
+		fs.IntVar(&estimate, "e", 0, "Estimated number of pomodoros (short form)")
+	case CmdList:
+		fs.BoolVar(&all, "all", false, "Include finished tasks")
+	}
+	fs.Parse(args[1:])
+	rest := fs.Args()
+
+	switch args[0] {
+	case CmdAdd:
+		name := strings.TrimSpace(strings.Join(rest, " "))
+		if name == "" || estimate < 0 {
+			return errUsage
+		}
+		return add(store, name, estimate, time.Now())
+	case CmdList:
+		tasks, err := store.Load()
+		if err != nil {
+			return err
+		}
+		List(os.Stdout, tasks, loadCounts(), all)
+		return nil
+	case CmdDone:
+		if len(rest) != 1 {
+			return errUsage
+		}
+		return update(store, rest[0], func(t *history.Task) string {
+			t.Done = true
+			return fmt.Sprintf("Task %d done: %s", t.ID, t.Name)
+		})
+	case CmdEstimate:
+		if len(rest) != 2 {
+			return errUsage
+		}
+		n, err := strconv.Atoi(rest[1])
+		if err != nil || n < 0 {
+			return fmt.Errorf("invalid estimate %q: expected a number of pomodoros", rest[1])
+		}
+		return update(store, rest[0], func(t *history.Task) string {
+			t.Estimate = n
+			return fmt.Sprintf("Task %d: %s, estimated at %s", t.ID, t.Name, pomodoros(n))
+		})
+	}
+	return errUsage
+}
+
+func add(store *history.TaskStore, name string, estimate int, now time.Time) error {
+	var added history.Task
+	err := store.Update(func(tasks []history.Task) ([]history.Task, error) {
+		if _, err := history.FindTask(tasks, name); err == nil {
+			return nil, fmt.Errorf("task %q already exists", name)
+		}
+		added = history.Task{ID: history.NextTaskID(tasks), Name: name, Estimate: estimate, Created: now}
+		return append(tasks, added), nil
+	})
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Added task %d: %s", added.ID, added.Name)
+	if added.Estimate > 0 {
+		fmt.Printf(" (%s)", pomodoros(added.Estimate))
+	}
+	fmt.Println()
+	return nil
+}
+
+// update changes the task named by ref and prints what change reports.
+func update(store *history.TaskStore, ref string, change func(*history.Task) string) error {
+	var message string
+	err := store.Update(func(tasks []history.Task) ([]history.Task, error) {
+		i, err := history.FindTask(tasks, ref)
+		if err != nil {
+			return nil, err
+		}
+		message = change(&tasks[i])
+		return tasks, nil
+	})
+	if err != nil {
+		return err
+	}
+	fmt.Println(message)
+	return nil
+}
+
+// loadCounts returns the completed pomodoros per task from the history.
+// Without a history every count is zero.
+func loadCounts() map[int]int {
+	path, err := history.DefaultPath()
+	if err != nil {
+		return nil
+	}
+	records, err := history.NewStore(path).Load()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: Could not read the session history: %v\n", err)
+		return nil
+	}
+	return history.CountPomodoros(records)
+}
+
+// List prints the open tasks, or all of them, with their completed
+// pomodoros against the estimate.
+func List(w io.Writer, tasks []history.Task, counts map[int]int, all bool) {
+	var shown []history.Task
+	for _, t := range tasks {
+		if all || !t.Done {
+			shown = append(shown, t)
+		}
+	}
+	if len(shown) == 0 {
+		fmt.Fprintln(w, "No open tasks. Add one with: termidoro task add <name>")
+		return
+	}
+	fmt.Fprintln(w, "  ID  Task                  Done  Estimate")
+	fmt.Fprintln(w, "  ────────────────────────────────────────")
+	for _, t := range shown {
+		fmt.Fprintf(w, "  %2d  %-20s  %4d  %s\n", t.ID, Truncate(t.Name, 20), counts[t.ID], Progress(counts[t.ID], t.Estimate, t.Done))
+	}
+}
+
+// Progress describes done pomodoros against an estimate, e.g. "4 (1 over)".
+func Progress(done, estimate int, finished bool) string {
+	var s string
+	switch {
+	case estimate == 0:
+		s = "-"
+	case done > estimate:
+		s = fmt.Sprintf("%d (%d over)", estimate, done-estimate)
+	default:
+		s = strconv.Itoa(estimate)
+	}
+	if finished {
+		s += " ✓"
+	}
+	return s
+}
+
+func pomodoros(n int) string {
+	if n == 1 {
+		return "1 pomodoro"
+	}
+	return fmt.Sprintf("%d pomodoros", n)
+}
+
+// Truncate shortens s to max runes for a table column, ending it with an
+// ellipsis when it was cut.
+func Truncate(s string, max int) string {
+	runes := []rune(s)
+	if len(runes) <= max {
+		return s
+	}
+	return string(runes[:max-1]) + "…"
+}
@@ -0,0 +1,47 @@
+package task
+
+import (
+	"strings"
+	"testing"
+
+	"termidoro/history"
+)
+
+func TestProgress(t *testing.T) {
+	tests := []struct {
+		done, estimate int
+		finished       bool
+		want           string
+	}{
+		{2, 4, false, "4"},
+		{5, 4, false, "4 (1 over)"},
+		{3, 0, false, "-"},
+		{4, 4, true, "4 ✓"},
+	}
+	for _, tt := range tests {
+		if got := Progress(tt.done, tt.estimate, tt.finished); got != tt.want {
+			t.Errorf("Progress(%d, %d, %v) = %q, want %q", tt.done, tt.estimate, tt.finished, got, tt.want)
+		}
+	}
+}
+
+func TestList(t *testing.T) {
+	tasks := []history.Task{
+		{ID: 1, Name: "Write report", Estimate: 4},
+		{ID: 2, Name: "Review PR", Done: true},
+	}
+	var out strings.Builder
+	List(&out, tasks, map[int]int{1: 2}, false)
+	if !strings.Contains(out.String(), "Write report             2  4") {
+		t.Errorf("Expected the open task with its progress, got:\n%s", out.String())
+	}
+	if strings.Contains(out.String(), "Review PR") {
+		t.Error("Expected finished tasks to be left out")
+	}
+
+	out.Reset()
+	List(&out, tasks, nil, true)
+	if !strings.Contains(out.String(), "Review PR") {
+		t.Errorf("Expected finished tasks with all, got:\n%s", out.String())
+	}
+}
@@ -2,6 +2,8 @@ package timer
 
 import (
 	"fmt"
+	"os"
+	"strings"
 	"time"
 )
 
@@ -10,55 +12,313 @@ type SessionType int
 const (
 	WORK SessionType = iota
 	BREAK
+	LONG_BREAK
 )
 
+func (t SessionType) String() string {
+	switch t {
+	case BREAK:
+		return "BREAK"
+	case LONG_BREAK:
+		return "LONG BREAK"
+	default:
+		return "WORK"
+	}
+}
+
 type Session struct {
-	Duration     time.Duration
+	// Duration is the planned length, including any extensions.
+	Duration time.Duration
+	// Elapsed is the time actually spent in the session, excluding pauses.
+	// It is set when the session finishes.
+	Elapsed      time.Duration
 	StartTime    time.Time
 	EndTime      time.Time
 	Completed    bool
 	WasCancelled bool
 	Type         SessionType
+	Name         string
+	Cycle        int
+	// Paused is the total time the session spent paused.
+	Paused time.Duration
+	// Extended is the net time added to or removed from the planned
+	// duration while the session was running.
+	Extended time.Duration
+	Restarts int
+	Skipped  bool
+	// Task is the ID of the task the session counts against, or 0.
+	Task int
+	// Interruptions are logged in the order they happened. Voided is set
+	// when the session was abandoned for having too many of them.
+	Interruptions []Interruption
+	Voided        bool
+	// Note is the journal entry on what was done in the session.
+	Note string
+	// Intention is what the work session set out to do, and Outcome one of
+	// the Outcome constants once it was reviewed.
+	Intention string
+	Outcome   string
+	pausedAt  time.Time
+	recorded  bool
+}
+
+// Answers to whether the intention of a session was achieved.
+const (
+	OutcomeYes    = "yes"
+	OutcomePartly = "partly"
+	OutcomeNo     = "no"
+)
+
+// Interruption is an internal or external interruption of a session, with
+// an optional note.
+type Interruption struct {
+	Time     time.Time
+	External bool
+	Note     string
+}
+
+// IsFinished reports whether the session was completed, cancelled, skipped
+// or voided.
+func (s Session) IsFinished() bool {
+	return s.Completed || s.WasCancelled || s.Skipped || s.Voided
+}
+
+// InterruptionCounts returns the number of internal and external
+// interruptions of the session.
+func (s Session) InterruptionCounts() (internal, external int) {
+	for _, i := range s.Interruptions {
+		if i.External {
+			external++
+		} else {
+			internal++
+		}
+	}
+	return internal, external
+}
+
+// IsPaused reports whether the session is currently paused.
+func (s Session) IsPaused() bool {
+	return !s.pausedAt.IsZero()
+}
+
+// Recorder persists sessions once they finish.
+type Recorder interface {
+	Record(Session) error
 }
 
 type Engine struct {
-	Sessions  []Session
+	Sessions []Session
+	// TotalTime is the actual focused time: the elapsed time of every
+	// finished work session.
 	TotalTime time.Duration
+	Name      string
+	Task      int
+	// Intention is given to the next work session that is added.
+	Intention string
+	Cycle     int
+	// HoldWork keeps finished work sessions from the recorder until
+	// RecordSession is called, so that the outcome and journal entry given
+	// after the session are saved with it in a single record.
+	HoldWork bool
+	recorder Recorder
+	now      func() time.Time
 }
 
 func NewEngine() *Engine {
 	return &Engine{
 		Sessions:  []Session{},
 		TotalTime: 0,
+		Cycle:     1,
+		now:       time.Now,
 	}
 }
 
-func (e *Engine) AddSession(duration time.Duration) {
-	sessionType := WORK
-	if len(e.Sessions)%2 == 1 { // Second session (index 1) is break
-		sessionType = BREAK
-	}
+func (e *Engine) SetRecorder(r Recorder) {
+	e.recorder = r
+}
 
+// SetClock replaces the clock that stamps sessions, e.g. with a fake one
+// in tests.
+func (e *Engine) SetClock(now func() time.Time) {
+	e.now = now
+}
+
+// TypeName is the identifier of a session type in the history file, events
+// and webhooks, e.g. "long_break".
+func TypeName(t SessionType) string {
+	return strings.ReplaceAll(strings.ToLower(t.String()), " ", "_")
+}
+
+// IsBreak reports whether t is a short or long break.
+func (t SessionType) IsBreak() bool {
+	return t == BREAK || t == LONG_BREAK
+}
+
+func (e *Engine) AddSession(duration time.Duration, sessionType SessionType) {
 	session := Session{
 		Duration:  duration,
-		StartTime: time.Now(),
-		EndTime:   time.Now().Add(duration),
+		StartTime: e.now(),
 		Completed: false,
 		Type:      sessionType,
+		Name:      e.Name,
+		Cycle:     e.Cycle,
+		Task:      e.Task,
+	}
+	if sessionType == WORK {
+		session.Intention = e.Intention
 	}
 	e.Sessions = append(e.Sessions, session)
 }
 
-func (e *Engine) CompleteSession(index int) {
+func (e *Engine) PauseSession(index int) {
+	if e.isOpen(index) && !e.Sessions[index].IsPaused() {
+		e.Sessions[index].pausedAt = e.now()
+	}
+}
+
+func (e *Engine) ResumeSession(index int) {
+	if e.isOpen(index) && e.Sessions[index].IsPaused() {
+		s := &e.Sessions[index]
+		s.Paused += e.now().Sub(s.pausedAt)
+		s.pausedAt = time.Time{}
+	}
+}
+
+// ExtendSession adds delta (which may be negative) to the planned duration.
+func (e *Engine) ExtendSession(index int, delta time.Duration) {
+	if e.isOpen(index) {
+		e.Sessions[index].Duration += delta
+		e.Sessions[index].Extended += delta
+	}
+}
+
+func (e *Engine) RestartSession(index int) {
+	if e.isOpen(index) {
+		e.Sessions[index].Restarts++
+	}
+}
+
+// SkipSession ends the session early without marking it completed.
+func (e *Engine) SkipSession(index int) {
+	if e.isOpen(index) {
+		e.Sessions[index].Skipped = true
+		e.finish(index)
+	}
+}
+
+// Interrupt logs an interruption of the session and returns how many it has
+// had so far.
+func (e *Engine) Interrupt(index int, external bool) int {
+	if !e.isOpen(index) {
+		return 0
+	}
+	s := &e.Sessions[index]
+	s.Interruptions = append(s.Interruptions, Interruption{Time: e.now(), External: external})
+	return len(s.Interruptions)
+}
+
+// NoteInterruption attaches note to the latest interruption of the session.
+func (e *Engine) NoteInterruption(index int, note string) {
+	if !e.isOpen(index) {
+		return
+	}
+	s := &e.Sessions[index]
+	if len(s.Interruptions) > 0 {
+		s.Interruptions[len(s.Interruptions)-1].Note = note
+	}
+}
+
+// SetNote saves a journal entry with the session.
+func (e *Engine) SetNote(index int, note string) {
+	if index >= 0 && index < len(e.Sessions) {
+		e.Sessions[index].Note = note
+	}
+}
+
+// SetOutcome saves whether the intention of a session was achieved.
+func (e *Engine) SetOutcome(index int, outcome string) {
 	if index >= 0 && index < len(e.Sessions) {
+		e.Sessions[index].Outcome = outcome
+	}
+}
+
+// RecordSession records a finished session held back by HoldWork. A session
+// is only ever recorded once.
+func (e *Engine) RecordSession(index int) {
+	if index >= 0 && index < len(e.Sessions) && e.Sessions[index].IsFinished() {
+		e.record(index)
+	}
+}
+
+// VoidSession ends a session that was interrupted too often. Like a
+// cancelled session it does not count as completed.
+func (e *Engine) VoidSession(index int) {
+	if e.isOpen(index) {
+		e.Sessions[index].Voided = true
+		e.finish(index)
+	}
+}
+
+func (e *Engine) CompleteSession(index int) {
+	if e.isOpen(index) {
 		e.Sessions[index].Completed = true
-		e.TotalTime += e.Sessions[index].Duration
+		e.finish(index)
 	}
 }
 
 func (e *Engine) CancelSession(index int) {
-	if index >= 0 && index < len(e.Sessions) {
+	if e.isOpen(index) {
 		e.Sessions[index].WasCancelled = true
+		e.finish(index)
+	}
+}
+
+// CancelOpenSessions cancels every session that has not finished yet, so
+// that no session is left without an end time when the run stops.
+func (e *Engine) CancelOpenSessions() {
+	for i := range e.Sessions {
+		e.CancelSession(i)
+	}
+}
+
+// finish stamps the end time and actual elapsed time of a session that has
+// just been marked completed, cancelled, skipped or voided, and records it
+// unless it is a work session held for review.
+func (e *Engine) finish(index int) {
+	s := &e.Sessions[index]
+	if s.IsPaused() {
+		s.Paused += e.now().Sub(s.pausedAt)
+		s.pausedAt = time.Time{}
+	}
+	s.EndTime = e.now()
+	s.Elapsed = s.EndTime.Sub(s.StartTime) - s.Paused
+	if s.Elapsed < 0 {
+		s.Elapsed = 0
+	}
+	if s.Type == WORK {
+		e.TotalTime += s.Elapsed
+	}
+	if !e.HoldWork || s.Type != WORK {
+		e.record(index)
+	}
+}
+
+// isOpen reports whether index refers to a session that has not yet
+// finished.
+func (e *Engine) isOpen(index int) bool {
+	if index < 0 || index >= len(e.Sessions) {
+		return false
+	}
+	return !e.Sessions[index].IsFinished()
+}
+
+func (e *Engine) record(index int) {
+	if e.recorder == nil || e.Sessions[index].recorded {
+		return
+	}
+	e.Sessions[index].recorded = true
+	if err := e.recorder.Record(e.Sessions[index]); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: Could not save session history: %v\n", err)
 	}
 }
 
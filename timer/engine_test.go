@@ -5,19 +5,31 @@ import (
 	"time"
 )
 
+// fakeClock returns a clock for Engine.now and a function advancing it.
+func fakeClock() (func() time.Time, func(time.Duration)) {
+	now := time.Date(2025, 3, 10, 9, 0, 0, 0, time.UTC)
+	return func() time.Time { return now }, func(d time.Duration) { now = now.Add(d) }
+}
+
 func TestEngine(t *testing.T) {
 	engine := NewEngine()
+	clock, advance := fakeClock()
+	engine.now = clock
 
 	// Test AddSession
-	engine.AddSession(10 * time.Minute)
+	engine.AddSession(10*time.Minute, WORK)
 	if len(engine.Sessions) != 1 {
 		t.Errorf("Expected 1 session, got %d", len(engine.Sessions))
 	}
+	if engine.Sessions[0].Type != WORK {
+		t.Errorf("Expected WORK session, got %v", engine.Sessions[0].Type)
+	}
 	if engine.Sessions[0].Duration != 10*time.Minute {
 		t.Errorf("Expected duration 10m, got %v", engine.Sessions[0].Duration)
 	}
 
 	// Test CompleteSession
+	advance(10 * time.Minute)
 	engine.CompleteSession(0)
 	if !engine.Sessions[0].Completed {
 		t.Error("Expected session to be completed")
@@ -26,13 +38,220 @@ func TestEngine(t *testing.T) {
 		t.Errorf("Expected total time to be 10m, got %v", engine.TotalTime)
 	}
 
+	if engine.Sessions[0].Elapsed != 10*time.Minute || !engine.Sessions[0].EndTime.Equal(clock()) {
+		t.Errorf("Expected 10m elapsed ending now, got %v ending %v", engine.Sessions[0].Elapsed, engine.Sessions[0].EndTime)
+	}
+
 	// Test CancelSession
-	engine.AddSession(5 * time.Minute)
+	engine.AddSession(5*time.Minute, BREAK)
+	advance(time.Minute)
 	engine.CancelSession(1)
 	if !engine.Sessions[1].WasCancelled {
 		t.Error("Expected session to be cancelled")
 	}
+	if engine.Sessions[1].Type != BREAK {
+		t.Errorf("Expected BREAK session, got %v", engine.Sessions[1].Type)
+	}
 	if engine.TotalTime != 10*time.Minute {
 		t.Errorf("Expected total time to remain 10m, got %v", engine.TotalTime)
 	}
 }
+
+func TestSessionTypeString(t *testing.T) {
+	if LONG_BREAK.String() != "LONG BREAK" {
+		t.Errorf("Expected LONG BREAK, got %s", LONG_BREAK.String())
+	}
+	if !LONG_BREAK.IsBreak() || !BREAK.IsBreak() || WORK.IsBreak() {
+		t.Error("Unexpected IsBreak result")
+	}
+	if TypeName(LONG_BREAK) != "long_break" || TypeName(WORK) != "work" {
+		t.Errorf("Unexpected type names %q and %q", TypeName(LONG_BREAK), TypeName(WORK))
+	}
+}
+
+func TestPauseResume(t *testing.T) {
+	engine := NewEngine()
+	clock, advance := fakeClock()
+	engine.now = clock
+	engine.AddSession(10*time.Minute, WORK)
+
+	engine.PauseSession(0)
+	if !engine.Sessions[0].IsPaused() {
+		t.Fatal("Expected session to be paused")
+	}
+	advance(2 * time.Minute)
+	engine.ResumeSession(0)
+	if engine.Sessions[0].IsPaused() {
+		t.Fatal("Expected session to be resumed")
+	}
+	if paused := engine.Sessions[0].Paused; paused != 2*time.Minute {
+		t.Errorf("Expected 2m paused, got %v", paused)
+	}
+
+	// Completing while paused folds the open pause into the total.
+	advance(time.Minute)
+	engine.PauseSession(0)
+	advance(3 * time.Minute)
+	engine.CompleteSession(0)
+	s := engine.Sessions[0]
+	if s.IsPaused() || s.Paused != 5*time.Minute || s.Elapsed != time.Minute {
+		t.Errorf("Expected the pause to be closed on completion, got %v paused and %v elapsed", s.Paused, s.Elapsed)
+	}
+}
+
+func TestAdjustments(t *testing.T) {
+	engine := NewEngine()
+	engine.AddSession(25*time.Minute, WORK)
+
+	engine.ExtendSession(0, 5*time.Minute)
+	engine.ExtendSession(0, 5*time.Minute)
+	engine.ExtendSession(0, -5*time.Minute)
+	engine.RestartSession(0)
+	s := engine.Sessions[0]
+	if s.Duration != 30*time.Minute || s.Extended != 5*time.Minute {
+		t.Errorf("Expected 30m duration and +5m extension, got %v and %v", s.Duration, s.Extended)
+	}
+	if s.Restarts != 1 {
+		t.Errorf("Expected 1 restart, got %d", s.Restarts)
+	}
+
+	engine.SkipSession(0)
+	if !engine.Sessions[0].Skipped || engine.Sessions[0].Completed {
+		t.Error("Expected session to be skipped and not completed")
+	}
+	engine.CompleteSession(0)
+	if engine.Sessions[0].Completed {
+		t.Error("Expected skipped session to stay closed")
+	}
+}
+
+func TestElapsedTime(t *testing.T) {
+	engine := NewEngine()
+	clock, advance := fakeClock()
+	engine.now = clock
+
+	// A work session cancelled after two minutes counts two minutes.
+	engine.AddSession(25*time.Minute, WORK)
+	advance(2 * time.Minute)
+	engine.CancelSession(0)
+	if engine.Sessions[0].Duration != 25*time.Minute || engine.Sessions[0].Elapsed != 2*time.Minute {
+		t.Errorf("Expected 25m planned and 2m elapsed, got %v and %v", engine.Sessions[0].Duration, engine.Sessions[0].Elapsed)
+	}
+
+	// Paused time is not focused time.
+	engine.AddSession(25*time.Minute, WORK)
+	advance(10 * time.Minute)
+	engine.PauseSession(1)
+	advance(5 * time.Minute)
+	engine.ResumeSession(1)
+	advance(15 * time.Minute)
+	engine.CompleteSession(1)
+	if engine.Sessions[1].Elapsed != 25*time.Minute || engine.Sessions[1].Paused != 5*time.Minute {
+		t.Errorf("Expected 25m elapsed and 5m paused, got %v and %v", engine.Sessions[1].Elapsed, engine.Sessions[1].Paused)
+	}
+
+	// Breaks do not add to the focused total.
+	engine.AddSession(5*time.Minute, BREAK)
+	advance(5 * time.Minute)
+	engine.CompleteSession(2)
+
+	if engine.TotalTime != 27*time.Minute {
+		t.Errorf("Expected 27m focused, got %v", engine.TotalTime)
+	}
+
+	// Open sessions are cancelled when the run stops.
+	engine.AddSession(25*time.Minute, WORK)
+	advance(time.Minute)
+	engine.CancelOpenSessions()
+	if !engine.Sessions[3].WasCancelled || engine.Sessions[2].WasCancelled {
+		t.Error("Expected only the open session to be cancelled")
+	}
+}
+
+func TestInterruptions(t *testing.T) {
+	engine := NewEngine()
+	clock, advance := fakeClock()
+	engine.now = clock
+
+	engine.AddSession(25*time.Minute, WORK)
+	advance(3 * time.Minute)
+	if n := engine.Interrupt(0, false); n != 1 {
+		t.Errorf("Expected 1 interruption, got %d", n)
+	}
+	engine.NoteInterruption(0, "check mail")
+	advance(time.Minute)
+	if n := engine.Interrupt(0, true); n != 2 {
+		t.Errorf("Expected 2 interruptions, got %d", n)
+	}
+
+	s := engine.Sessions[0]
+	if s.Interruptions[0].Note != "check mail" || s.Interruptions[1].Note != "" {
+		t.Errorf("Expected the note on the first interruption, got %+v", s.Interruptions)
+	}
+	if !s.Interruptions[1].Time.Equal(clock()) {
+		t.Errorf("Expected the second interruption at %v, got %v", clock(), s.Interruptions[1].Time)
+	}
+	if internal, external := s.InterruptionCounts(); internal != 1 || external != 1 {
+		t.Errorf("Expected 1 internal and 1 external, got %d and %d", internal, external)
+	}
+
+	engine.VoidSession(0)
+	s = engine.Sessions[0]
+	if !s.Voided || s.Completed || !s.IsFinished() {
+		t.Error("Expected session to be voided and finished")
+	}
+	if s.Elapsed != 4*time.Minute || engine.TotalTime != 4*time.Minute {
+		t.Errorf("Expected 4m elapsed, got %v", s.Elapsed)
+	}
+	if n := engine.Interrupt(0, false); n != 0 || len(engine.Sessions[0].Interruptions) != 2 {
+		t.Error("Expected a finished session to take no more interruptions")
+	}
+}
+
+// recorder keeps every session it is asked to record.
+type recorder []Session
+
+func (r *recorder) Record(s Session) error {
+	*r = append(*r, s)
+	return nil
+}
+
+func TestHoldWork(t *testing.T) {
+	engine := NewEngine()
+	var recorded recorder
+	engine.SetRecorder(&recorded)
+	engine.HoldWork = true
+
+	engine.Intention = "finish parser tests"
+	engine.AddSession(25*time.Minute, WORK)
+	engine.CompleteSession(0)
+	if len(recorded) != 0 {
+		t.Fatalf("Expected a held work session not to be recorded, got %d records", len(recorded))
+	}
+	engine.SetOutcome(0, OutcomePartly)
+	engine.SetNote(0, "Wrote the parser tests")
+	engine.RecordSession(0)
+	engine.RecordSession(0)
+	if len(recorded) != 1 || recorded[0].Note != "Wrote the parser tests" || recorded[0].Outcome != OutcomePartly {
+		t.Errorf("Expected one record with the note and outcome, got %+v", recorded)
+	}
+
+	// Breaks are not held, and open sessions are never recorded.
+	engine.AddSession(5*time.Minute, BREAK)
+	engine.RecordSession(1)
+	engine.CompleteSession(1)
+	if len(recorded) != 2 || recorded[1].Type != BREAK {
+		t.Errorf("Expected the break to be recorded when it finished, got %+v", recorded)
+	}
+}
+
+func TestIntention(t *testing.T) {
+	engine := NewEngine()
+
+	engine.Intention = "finish parser tests"
+	engine.AddSession(25*time.Minute, WORK)
+	engine.AddSession(5*time.Minute, BREAK)
+	if engine.Sessions[0].Intention != "finish parser tests" || engine.Sessions[1].Intention != "" {
+		t.Errorf("Expected only the work session to take the intention, got %q and %q", engine.Sessions[0].Intention, engine.Sessions[1].Intention)
+	}
+}
@@ -0,0 +1,78 @@
+package ui
+
+import (
+	"errors"
+	"os"
+	"strings"
+	"sync"
+
+	"golang.org/x/term"
+)
+
+// Key codes read from the terminal in raw mode.
+const (
+	KeyCtrlC     = 3
+	KeyEnter     = '\r'
+	KeyEscape    = 27
+	KeyBackspace = 127
+)
+
+var (
+	keys     chan byte
+	keysOnce sync.Once
+)
+
+var errNotTerminal = errors.New("stdin is not a terminal")
+
+// Keys returns the bytes read from stdin. A single reader goroutine is started
+// on first use and shared for the rest of the process, so in-session key
+// presses and line prompts never compete for input. The channel is closed
+// when stdin reaches EOF.
+func Keys() <-chan byte {
+	keysOnce.Do(func() {
+		keys = make(chan byte, 64)
+		go func() {
+			buf := make([]byte, 64)
+			for {
+				n, err := os.Stdin.Read(buf)
+				for _, b := range buf[:n] {
+					keys <- b
+				}
+				if err != nil {
+					close(keys)
+					return
+				}
+			}
+		}()
+	})
+	return keys
+}
+
+// ReadLine reads one line from Keys without the trailing newline.
+func ReadLine() string {
+	var line strings.Builder
+	for b := range Keys() {
+		if b == '\n' || b == '\r' {
+			break
+		}
+		line.WriteByte(b)
+	}
+	return line.String()
+}
+
+// EnableRawMode switches the terminal on stdin to raw mode so single key
+// presses are delivered immediately. The returned function restores the
+// previous mode.
+func EnableRawMode() (func(), error) {
+	fd := int(os.Stdin.Fd())
+	if !term.IsTerminal(fd) {
+		return nil, errNotTerminal
+	}
+	state, err := term.MakeRaw(fd)
+	if err != nil {
+		return nil, err
+	}
+	return func() {
+		term.Restore(fd, state)
+	}, nil
+}
@@ -1,8 +1,8 @@
 package ui
 
 import (
-	"bufio"
 	"fmt"
+	"io"
 	"math"
 	"os"
 	"strings"
@@ -21,8 +21,37 @@ type Renderer struct {
 	customName  string
 	termWidth   int
 	termHeight  int
+	paused      bool
+	// alert is the latest warning or milestone, shown on the message line
+	// until the session ends.
+	alert string
+	// interruptions is shown in the title line once there is one, out of
+	// interruptionLimit when a limit is set.
+	interruptions     int
+	interruptionLimit int
+	// intention is what the work session set out to do.
+	intention string
 }
 
+// maxIntention is the longest intention shown in the title line.
+const maxIntention = 40
+
+// out receives everything the renderer draws.
+var out io.Writer = os.Stdout
+
+// SetOutput redirects all drawing, e.g. to io.Discard when another output
+// mode owns stdout.
+func SetOutput(w io.Writer) {
+	out = w
+}
+
+// ShowCursor makes the terminal cursor visible again.
+func ShowCursor() {
+	fmt.Fprint(out, "\033[?25h")
+}
+
+const pausedMessage = "Paused - press p or space to resume"
+
 type RGB struct {
 	R, G, B int
 }
@@ -47,6 +76,10 @@ func getBreakGradientColors() (RGB, RGB) {
 	return RGB{251, 146, 60}, RGB{239, 68, 68} // Orange to Red
 }
 
+func getLongBreakGradientColors() (RGB, RGB) {
+	return RGB{45, 212, 191}, RGB{34, 197, 94} // Teal to Green
+}
+
 func NewRenderer(totalSeconds int64, sessionNum int, sessionType timer.SessionType, cycleNum int, customName ...string) *Renderer {
 	// Get terminal size
 	width, height, err := term.GetSize(int(os.Stdout.Fd()))
@@ -62,8 +95,8 @@ func NewRenderer(totalSeconds int64, sessionNum int, sessionType timer.SessionTy
 		} else {
 			displayName = "WORK"
 		}
-	} else { // sessionType == timer.BREAK
-		displayName = "BREAK"
+	} else { // BREAK or LONG_BREAK
+		displayName = sessionType.String()
 	}
 
 	return &Renderer{
@@ -79,7 +112,7 @@ func NewRenderer(totalSeconds int64, sessionNum int, sessionType timer.SessionTy
 }
 
 func (r *Renderer) Start() {
-	fmt.Print("\033[?25l")
+	fmt.Fprint(out, "\033[?25l")
 	r.DrawHeader()
 }
 
@@ -91,36 +124,98 @@ func (r *Renderer) SetTotal(total int64) {
 	r.totalSecs = total
 }
 
+// Reset starts the countdown over from zero.
+func (r *Renderer) Reset() {
+	r.current = 0
+	r.alert = ""
+	if !r.paused {
+		r.ClearMessage()
+	}
+}
+
+// SetPaused switches the PAUSED indicator in the title line on or off.
+func (r *Renderer) SetPaused(paused bool) {
+	r.paused = paused
+	r.drawTitle()
+	r.drawMessage()
+}
+
+// SetIntention shows intention in the title line, after the cycle.
+func (r *Renderer) SetIntention(intention string) {
+	if runes := []rune(intention); len(runes) > maxIntention {
+		intention = string(runes[:maxIntention-1]) + "…"
+	}
+	r.intention = intention
+	r.drawTitle()
+}
+
+// SetInterruptions updates the interruption count in the title line. A
+// limit above zero is shown next to it.
+func (r *Renderer) SetInterruptions(count, limit int) {
+	r.interruptions = count
+	r.interruptionLimit = limit
+	r.drawTitle()
+}
+
+// ShowAlert shows a warning or milestone on the message line. While the
+// session is paused, the paused message takes precedence.
+func (r *Renderer) ShowAlert(message string) {
+	r.alert = message
+	r.drawMessage()
+}
+
+func (r *Renderer) drawMessage() {
+	switch {
+	case r.paused:
+		r.DisplayMessage(pausedMessage)
+	case r.alert != "":
+		r.DisplayMessage(fmt.Sprintf("\033[33m%s\033[0m", r.alert))
+	default:
+		r.ClearMessage()
+	}
+}
+
+func (r *Renderer) IsPaused() bool {
+	return r.paused
+}
+
 func (r *Renderer) Finish() {
-	fmt.Print("\033[?25h")
-	fmt.Print("\033[4B\n")
+	fmt.Fprint(out, "\033[?25h")
+	fmt.Fprint(out, "\033[4B\n")
 }
 
 func (r *Renderer) DisplayMessage(message string) {
 	// Display message below the timer UI (line 6)
-	fmt.Printf("\033[6;1H\033[K%s", message)
+	fmt.Fprintf(out, "\033[6;1H\033[K%s", message)
 }
 
 func (r *Renderer) ClearMessage() {
 	// Clear the message line (line 6)
-	fmt.Printf("\033[6;1H\033[K")
+	fmt.Fprintf(out, "\033[6;1H\033[K")
 }
 
-func (r *Renderer) PromptContinue() bool {
-	// Show prompt below the timer UI (line 7)
-	fmt.Printf("\033[7;1H\033[KContinue with another cycle? [Y/n]: ")
-	fmt.Print("\033[?25h") // Show cursor for input
+// ShowPrompt asks question below the timer UI (line 7) and shows the cursor
+// for the answer.
+func (r *Renderer) ShowPrompt(question string) {
+	fmt.Fprintf(out, "\033[7;1H\033[K%s", question)
+	fmt.Fprint(out, "\033[?25h") // Show cursor for input
+}
 
-	reader := bufio.NewReader(os.Stdin)
-	input, _ := reader.ReadString('\n')
-	input = strings.TrimSpace(strings.ToLower(input))
+// HidePrompt hides the cursor again once the prompt is answered.
+func (r *Renderer) HidePrompt() {
+	fmt.Fprint(out, "\033[?25l")
+}
 
-	fmt.Print("\033[?25l") // Hide cursor again
+// ClearPrompt removes an answered prompt from line 7 and hides the cursor.
+func (r *Renderer) ClearPrompt() {
+	fmt.Fprint(out, "\033[7;1H\033[K")
+	r.HidePrompt()
+}
 
-	if input == "n" || input == "no" {
-		return false
-	}
-	return true
+// Declined reports whether the answer to a [Y/n] prompt is no.
+func Declined(answer string) bool {
+	answer = strings.TrimSpace(strings.ToLower(answer))
+	return answer == "n" || answer == "no"
 }
 
 func (r *Renderer) UpdateTerminalSize() {
@@ -145,12 +240,12 @@ func (r *Renderer) DrawTimeLeft(elapsed, total time.Duration) {
 	// Position progress bar at line 3, column 2 (inside box)
 	progressX := 2
 	progressY := 3
-	fmt.Printf("\033[%d;%dH\033[K%s  %.0f%%", progressY, progressX, bar, percent)
+	fmt.Fprintf(out, "\033[%d;%dH\033[K%s  %.0f%%", progressY, progressX, bar, percent)
 
 	// Position time remaining at line 3, column 60 (inside box, right side)
 	timeX := 60
 	timeY := 3
-	fmt.Printf("\033[%d;%dH%dm %02ds left", timeY, timeX, int(remaining.Minutes()), int(remaining.Seconds())%60)
+	fmt.Fprintf(out, "\033[%d;%dH%dm %02ds left", timeY, timeX, int(remaining.Minutes()), int(remaining.Seconds())%60)
 }
 
 func (r *Renderer) DrawPercentage(elapsed, total time.Duration) {
@@ -159,7 +254,7 @@ func (r *Renderer) DrawPercentage(elapsed, total time.Duration) {
 	// Position percentage at line 3, column 2 (inside box)
 	progressX := 2
 	progressY := 3
-	fmt.Printf("\033[%d;%dH\033[K%s  %.0f%%", progressY, progressX, bar, percent)
+	fmt.Fprintf(out, "\033[%d;%dH\033[K%s  %.0f%%", progressY, progressX, bar, percent)
 }
 
 func (r *Renderer) createProgressBar(percent float64) string {
@@ -174,9 +269,12 @@ func (r *Renderer) createProgressBar(percent float64) string {
 	empty := width - filled
 
 	var startColor, endColor RGB
-	if r.sessionType == timer.WORK {
+	switch r.sessionType {
+	case timer.WORK:
 		startColor, endColor = getWorkGradientColors()
-	} else {
+	case timer.LONG_BREAK:
+		startColor, endColor = getLongBreakGradientColors()
+	default:
 		startColor, endColor = getBreakGradientColors()
 	}
 
@@ -198,43 +296,64 @@ func (r *Renderer) createProgressBar(percent float64) string {
 }
 
 func (r *Renderer) DrawHeader() {
-	fmt.Print("\033[2J\033[H")
+	fmt.Fprint(out, "\033[2J\033[H")
 
-	// Draw session type and cycle number at top (line 1)
-	fmt.Printf("\033[1;1H[%s Cycle %d]", r.customName, r.cycleNum)
+	r.drawTitle()
 
 	// Draw complete box borders starting at line 2
-	fmt.Printf("\033[2;1H┌─────────────────────────────────────────────────────────────────────┐")
-	fmt.Printf("\033[3;1H│                                                                 │")
-	fmt.Printf("\033[4;1H└─────────────────────────────────────────────────────────────────────┘")
+	fmt.Fprintf(out, "\033[2;1H┌─────────────────────────────────────────────────────────────────────┐")
+	fmt.Fprintf(out, "\033[3;1H│                                                                 │")
+	fmt.Fprintf(out, "\033[4;1H└─────────────────────────────────────────────────────────────────────┘")
+
+	if r.paused || r.alert != "" {
+		r.drawMessage()
+	}
+}
+
+func (r *Renderer) drawTitle() {
+	// Draw session type and cycle number at top (line 1)
+	fmt.Fprintf(out, "\033[1;1H\033[K[%s Cycle %d]", r.customName, r.cycleNum)
+	if r.intention != "" {
+		fmt.Fprintf(out, " \033[1m%s\033[0m", r.intention)
+	}
+	if r.interruptions > 0 {
+		count := fmt.Sprint(r.interruptions)
+		if r.interruptionLimit > 0 {
+			count += fmt.Sprintf("/%d", r.interruptionLimit)
+		}
+		fmt.Fprintf(out, " Interruptions: %s", count)
+	}
+	if r.paused {
+		fmt.Fprint(out, " \033[1;33mPAUSED\033[0m")
+	}
 }
 
 func (r *Renderer) ClearScreen() {
-	fmt.Print("\033[2J\033[H")
+	fmt.Fprint(out, "\033[2J\033[H")
 }
 
 func (r *Renderer) RestoreCursor() {
-	fmt.Print("\033[?25h")
+	fmt.Fprint(out, "\033[?25h")
 }
 
 func (r *Renderer) SaveCursor() {
-	fmt.Print("\033[s")
+	fmt.Fprint(out, "\033[s")
 }
 
 func (r *Renderer) MoveToLineStart() {
-	fmt.Print("\r")
+	fmt.Fprint(out, "\r")
 }
 
 func (r *Renderer) EraseLine() {
-	fmt.Print("\033[2K")
+	fmt.Fprint(out, "\033[2K")
 }
 
 func (r *Renderer) FinalMessage(sessionNum int, cycleNum int) {
-	fmt.Printf("\n\n%s Cycle %d completed!\n\n", r.customName, cycleNum)
+	fmt.Fprintf(out, "\n\n%s Cycle %d completed!\n\n", r.customName, cycleNum)
 }
 
 func (r *Renderer) CancelledMessage(sessionNum int, cycleNum int) {
-	fmt.Printf("\n\n%s Cycle %d cancelled\n", r.customName, cycleNum)
+	fmt.Fprintf(out, "\n\n%s Cycle %d cancelled\n", r.customName, cycleNum)
 }
 
 func FormatDuration(d time.Duration) string {
@@ -249,21 +368,102 @@ func FormatDurationShort(d time.Duration) string {
 	return fmt.Sprintf("%dm %02ds", minutes, seconds)
 }
 
-func PrintRecap(sessions []struct {
+// RecapEntry is one preformatted line of the end-of-run recap. Duration is
+// the actual elapsed time; Planned is only set when it differs.
+type RecapEntry struct {
+	Type      string
 	Duration  string
+	Planned   string
 	StartTime string
 	EndTime   string
+	Paused    string
+	Extended  string
+	Restarts  int
 	Completed bool
 	Cancelled bool
-}, totalTime time.Duration) {
-	fmt.Println()
-	fmt.Println("--- Session Recap ---")
+	Skipped   bool
+	Voided    bool
+	// Interruptions is the number of interruptions logged in the session.
+	Interruptions int
+	// Note is the journal entry of the session, shown below its line.
+	Note string
+	// Intention and Outcome are shown below the line too, once set.
+	Intention string
+	Outcome   string
+}
+
+func PrintRecap(sessions []RecapEntry, totalTime time.Duration) {
+	fmt.Fprintln(out)
+	fmt.Fprintln(out, "--- Session Recap ---")
 	for i, s := range sessions {
 		status := "✓"
-		if s.Cancelled {
+		if s.Cancelled || s.Voided {
 			status = "✗"
+		} else if s.Skipped {
+			status = "⏭"
+		}
+		duration := s.Duration
+		if s.Planned != "" {
+			duration += " of " + s.Planned
+		}
+		fmt.Fprintf(out, "%d. %-10s %s - %s - %s %s", i+1, s.Type, duration, s.StartTime, s.EndTime, status)
+
+		var changes []string
+		if s.Paused != "" {
+			changes = append(changes, "paused "+s.Paused)
 		}
-		fmt.Printf("%d. %s - %s - %s %s\n", i+1, s.Duration, s.StartTime, s.EndTime, status)
+		if s.Extended != "" {
+			changes = append(changes, s.Extended)
+		}
+		if s.Restarts > 0 {
+			changes = append(changes, fmt.Sprintf("restarted %dx", s.Restarts))
+		}
+		if s.Skipped {
+			changes = append(changes, "skipped")
+		}
+		if s.Interruptions == 1 {
+			changes = append(changes, "1 interruption")
+		} else if s.Interruptions > 1 {
+			changes = append(changes, fmt.Sprintf("%d interruptions", s.Interruptions))
+		}
+		if s.Voided {
+			changes = append(changes, "voided")
+		}
+		if len(changes) > 0 {
+			fmt.Fprintf(out, " (%s)", strings.Join(changes, ", "))
+		}
+		fmt.Fprintln(out)
+		if s.Intention != "" {
+			fmt.Fprintf(out, "   Intention: %s", s.Intention)
+			if s.Outcome != "" {
+				fmt.Fprintf(out, " (%s)", s.Outcome)
+			}
+			fmt.Fprintln(out)
+		}
+		if s.Note != "" {
+			fmt.Fprintf(out, "   Done: %s\n", s.Note)
+		}
+	}
+	fmt.Fprintf(out, "Total focused: %s\n", FormatDuration(totalTime))
+}
+
+// PrintInterruptionRecap adds the interruptions of the run's work sessions
+// to the recap, with the average per session.
+func PrintInterruptionRecap(internal, external, sessions int) {
+	total := internal + external
+	if total == 0 || sessions == 0 {
+		return
+	}
+	fmt.Fprintf(out, "Interruptions: %d (%d internal, %d external), %.1f per session\n",
+		total, internal, external, float64(total)/float64(sessions))
+}
+
+// PrintTaskRecap ends the recap with the pomodoros done on the run's task,
+// against its estimate when it has one.
+func PrintTaskRecap(name string, done, estimate int) {
+	if estimate > 0 {
+		fmt.Fprintf(out, "Task %s: %d of %d pomodoros\n", name, done, estimate)
+		return
 	}
-	fmt.Printf("Total: %s\n", FormatDuration(totalTime))
+	fmt.Fprintf(out, "Task %s: %d pomodoros\n", name, done)
 }